@@ -0,0 +1,280 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunternl/trafficmap/coordinate"
+	"github.com/hunternl/trafficmap/imageinfo"
+	"github.com/hunternl/trafficmap/traveltime"
+)
+
+// Response types for /api/v1/
+// These are kept apart from Drip so internal changes don't leak into the API
+
+type ApiImage struct {
+	// Content addressed, changes whenever the image does
+	Url  string `json:"url"`
+	Hash string `json:"hash"`
+	// Similar images have perceptual hashes that differ in few bits
+	PerceptualHash imageinfo.Hash     `json:"perceptualHash"`
+	Analysis       imageinfo.Analysis `json:"analysis"`
+	Width          int                `json:"width"`
+	Height         int                `json:"height"`
+	// Drawn from the text lines, for panels that only send text
+	Rendered bool `json:"rendered"`
+}
+
+type ApiDrip struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// Null when the location table holds no usable position
+	Lat              *float64           `json:"lat"`
+	Lon              *float64           `json:"lon"`
+	RdX              *float64           `json:"rdX"`
+	RdY              *float64           `json:"rdY"`
+	CoordinateStatus coordinate.Status  `json:"coordinateStatus"`
+	Working          bool               `json:"working"`
+	DisplayState     DisplayState       `json:"displayState"`
+	Organization     string             `json:"organization"`
+	OrganizationCode string             `json:"organizationCode"`
+	RoadId           string             `json:"roadId"`
+	RoadSide         string             `json:"roadSide"`
+	RoadOffset       *int               `json:"roadOffset"`
+	Carriageway      string             `json:"carriageway"`
+	Junction         string             `json:"junction"`
+	HectometerLetter string             `json:"hectometerLetter"`
+	TextLines        []string           `json:"text"`
+	Routes           []traveltime.Route `json:"routes"`
+	Image            *ApiImage          `json:"image"`
+}
+
+type ApiDripList struct {
+	DateUpdated time.Time `json:"dateUpdated"`
+	// Number of DRIPs matching the filters, regardless of pagination
+	Total  int       `json:"total"`
+	Offset int       `json:"offset"`
+	Drips  []ApiDrip `json:"drips"`
+}
+
+type ApiDistinctImage struct {
+	ApiImage
+	// Group of near-duplicate images this image belongs to
+	Group   int      `json:"group"`
+	Count   int      `json:"count"`
+	DripIds []string `json:"dripIds"`
+}
+
+type ApiImageGroup struct {
+	Group int `json:"group"`
+	// Number of distinct images in the group
+	Images int `json:"images"`
+	// Number of DRIPs showing an image from the group
+	Count int `json:"count"`
+}
+
+type ApiImageList struct {
+	DateUpdated time.Time          `json:"dateUpdated"`
+	Images      []ApiDistinctImage `json:"images"`
+	Groups      []ApiImageGroup    `json:"groups"`
+}
+
+type ApiError struct {
+	Error string `json:"error"`
+}
+
+func toApiDrip(d Drip) ApiDrip {
+	out := ApiDrip{
+		Id:               d.Id,
+		Name:             d.Name,
+		CoordinateStatus: d.CoordinateStatus,
+		Working:          d.Working,
+		DisplayState:     d.DisplayState,
+		Organization:     d.Organization,
+		OrganizationCode: d.OrganizationCode,
+		RoadId:           d.RoadId,
+		RoadSide:         d.RoadSide,
+		Carriageway:      d.Carriageway,
+		Junction:         d.Junction,
+		HectometerLetter: d.HectometerLetter,
+		TextLines:        d.TextLines,
+		Routes:           d.Routes,
+	}
+
+	if out.TextLines == nil {
+		out.TextLines = make([]string, 0)
+	}
+
+	if out.Routes == nil {
+		out.Routes = make([]traveltime.Route, 0)
+	}
+
+	if d.CoordinateStatus != coordinate.StatusInvalid {
+		lat, lon, rdX, rdY := d.Latitude, d.Longitude, d.RdX, d.RdY
+		out.Lat, out.Lon, out.RdX, out.RdY = &lat, &lon, &rdX, &rdY
+	}
+
+	if d.RoadOffset >= 0 {
+		offset := d.RoadOffset
+		out.RoadOffset = &offset
+	}
+
+	if d.hasMarkerImage() {
+		out.Image = &ApiImage{
+			Url:            contentImagePath(d.ImageHash, "png"),
+			Hash:           d.ImageHash,
+			PerceptualHash: d.PerceptualHash,
+			Width:          d.ImageWidth,
+			Height:         d.ImageHeight,
+			Rendered:       d.ImageRendered,
+		}
+	}
+
+	// Rendered images aren't analysed, they get an empty analysis
+	if out.Image != nil && d.ImageAnalysis != nil {
+		out.Image.Analysis = *d.ImageAnalysis
+	} else if out.Image != nil {
+		out.Image.Analysis = imageinfo.Analysis{DominantColors: make([]string, 0), TextAreas: make([]imageinfo.Rect, 0)}
+	}
+
+	return out
+}
+
+func writeJson(w http.ResponseWriter, data any) {
+	str, err := json.Marshal(data)
+	if err != nil {
+		fmt.Println(err.Error())
+		w.WriteHeader(500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(str)
+}
+
+func writeApiError(w http.ResponseWriter, status int, message string) {
+	str, _ := json.Marshal(ApiError{Error: message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(str)
+}
+
+// Reads a non-negative integer query parameter, returning fallback if absent
+func intParam(r *http.Request, name string, fallback int) (int, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return fallback, nil
+	}
+
+	num, err := strconv.Atoi(str)
+	if err != nil || num < 0 {
+		return 0, fmt.Errorf("%v should be a non-negative integer", name)
+	}
+
+	return num, nil
+}
+
+// Serves all DRIPs, or a page of them when offset and/or limit are given
+// Filters are applied before paginating
+func handleApiDrips(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := dripFilterFromQuery(r)
+		if err != nil {
+			writeApiError(w, 400, err.Error())
+			return
+		}
+
+		offset, err := intParam(r, "offset", 0)
+		if err != nil {
+			writeApiError(w, 400, err.Error())
+			return
+		}
+
+		limit, err := intParam(r, "limit", -1)
+		if err != nil {
+			writeApiError(w, 400, err.Error())
+			return
+		}
+
+		serv.Lock()
+		defer serv.Unlock()
+
+		drips := filterDrips(serv.DripsSlice, filter)
+		total := len(drips)
+		offset = min(offset, total)
+		drips = pageDrips(drips, offset, limit)
+
+		out := ApiDripList{
+			DateUpdated: serv.LastUpdate,
+			Total:       total,
+			Offset:      offset,
+			Drips:       make([]ApiDrip, len(drips)),
+		}
+
+		for i, drip := range drips {
+			out.Drips[i] = toApiDrip(drip)
+		}
+
+		writeJson(w, out)
+	})
+}
+
+func handleApiDrip(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/drips/")
+
+		serv.Lock()
+		drip, found := serv.dripsMap[id]
+		serv.Unlock()
+
+		if !found {
+			writeApiError(w, 404, "no DRIP with id "+id)
+			return
+		}
+
+		writeJson(w, toApiDrip(drip))
+	})
+}
+
+func handleApiOrganizations(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serv.Lock()
+		defer serv.Unlock()
+
+		writeJson(w, countOrganizations(serv.DripsSlice))
+	})
+}
+
+func handleApiImages(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serv.Lock()
+		defer serv.Unlock()
+
+		writeJson(w, distinctImages(serv.DripsSlice, serv.LastUpdate))
+	})
+}
+
+//go:embed openapi.json
+var openApiSpec []byte
+
+func handleOpenApi() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openApiSpec)
+	})
+}
+
+// Registers every /api/v1/ route, these should match the paths in openapi.json
+func registerApi(mux *http.ServeMux, serv *DripServ) {
+	mux.Handle("/api/v1/drips", handleApiDrips(serv))
+	mux.Handle("/api/v1/drips/", handleApiDrip(serv))
+	mux.Handle("/api/v1/images", handleApiImages(serv))
+	mux.Handle("/api/v1/organizations", handleApiOrganizations(serv))
+	mux.Handle("/api/v1/openapi.json", handleOpenApi())
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestServ(t *testing.T) *DripServ {
+	t.Helper()
+
+	vmsUnits, err := os.ReadFile("./testdata/vmsUnit.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vmsRecords, err := os.ReadFile("./testdata/vmsRecord.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drips, err := ParseDripsXML(vmsUnits, vmsRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serv := newServ()
+	serv.DripsSlice = drips
+	for _, drip := range drips {
+		serv.dripsMap[drip.Id] = drip
+		if drip.hasMarkerImage() {
+			serv.imagesByHash[drip.ImageHash] = drip.image
+		}
+	}
+
+	return &serv
+}
+
+type openApiSpecDoc struct {
+	Paths map[string]map[string]struct {
+		Parameters []struct {
+			Name    string `json:"name"`
+			In      string `json:"in"`
+			Example string `json:"example"`
+		} `json:"parameters"`
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema map[string]any `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+// Checks a decoded JSON value against a subset of OpenAPI schemas
+// Objects may not have properties missing from the schema, so internal fields can't leak out
+func validateSchema(spec *openApiSpecDoc, schema map[string]any, value any, path string) []string {
+	if ref, found := schema["$ref"].(string); found {
+		return validateSchema(spec, spec.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")], value, path)
+	}
+
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return []string{path + " is null"}
+	}
+
+	errs := make([]string, 0)
+
+	if allOf, found := schema["allOf"].([]any); found {
+		for _, sub := range allOf {
+			errs = append(errs, validateSchema(spec, sub.(map[string]any), value, path)...)
+		}
+	}
+
+	if enum, found := schema["enum"].([]any); found {
+		matched := false
+		for _, option := range enum {
+			matched = matched || option == value
+		}
+		if !matched {
+			errs = append(errs, fmt.Sprintf("%v is %v, not one of %v", path, value, enum))
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return append(errs, path+" is not an object")
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+
+		for _, name := range required {
+			if _, found := object[name.(string)]; !found {
+				errs = append(errs, fmt.Sprintf("%v is missing required property %v", path, name))
+			}
+		}
+
+		for name, propertyValue := range object {
+			property, found := properties[name]
+			if !found {
+				errs = append(errs, fmt.Sprintf("%v has undocumented property %v", path, name))
+				continue
+			}
+			errs = append(errs, validateSchema(spec, property.(map[string]any), propertyValue, path+"."+name)...)
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return append(errs, path+" is not an array")
+		}
+		for i, item := range array {
+			errs = append(errs, validateSchema(spec, schema["items"].(map[string]any), item, fmt.Sprintf("%v[%v]", path, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			errs = append(errs, path+" is not a string")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, path+" is not a boolean")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			errs = append(errs, path+" is not a number")
+		}
+	case "integer":
+		if num, ok := value.(float64); !ok || num != math.Trunc(num) {
+			errs = append(errs, path+" is not an integer")
+		}
+	}
+
+	return errs
+}
+
+// Builds a request URL that should produce the given status for an operation
+func exampleUrl(path string, params []struct {
+	Name    string `json:"name"`
+	In      string `json:"in"`
+	Example string `json:"example"`
+}, status int) string {
+	query := make([]string, 0)
+
+	for _, param := range params {
+		switch param.In {
+		case "path":
+			value := param.Example
+			if status == 404 {
+				value = "UNKNOWN_ID"
+			}
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", value)
+		case "query":
+			if status == 400 {
+				query = append(query, param.Name+"=invalid")
+			}
+		}
+	}
+
+	if len(query) > 0 {
+		return path + "?" + strings.Join(query, "&")
+	}
+	return path
+}
+
+func TestApiMatchesSpec(t *testing.T) {
+	var spec openApiSpecDoc
+	if err := json.Unmarshal(openApiSpec, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := createMux(newTestServ(t))
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for method, operation := range spec.Paths[path] {
+			for code, response := range operation.Responses {
+				status, err := strconv.Atoi(code)
+				if err != nil {
+					t.Fatalf("Unexpected response code %v for %v", code, path)
+				}
+
+				url := exampleUrl(path, operation.Parameters, status)
+				t.Run(strings.ToUpper(method)+" "+url, func(t *testing.T) {
+					recorder := httptest.NewRecorder()
+					mux.ServeHTTP(recorder, httptest.NewRequest(strings.ToUpper(method), url, nil))
+
+					if recorder.Code != status {
+						t.Fatalf("Expected status %v, got %v", status, recorder.Code)
+					}
+
+					content, found := response.Content["application/json"]
+					if !found {
+						return
+					}
+
+					if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+						t.Fatalf("Expected JSON, got %v", contentType)
+					}
+
+					var body any
+					if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+						t.Fatal(err)
+					}
+
+					for _, err := range validateSchema(&spec, content.Schema, body, "response") {
+						t.Error(err)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestApiPagination(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/drips?offset=1&limit=1", nil))
+
+	var page ApiDripList
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, page.Total, 3)
+	assert(t, page.Offset, 1)
+	assert(t, len(page.Drips), 1)
+	assert(t, page.Drips[0].Id, "ID_2")
+	assert(t, *page.Drips[0].Lat, 52.3)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/drips?offset=10", nil))
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, page.Offset, 3)
+	assert(t, len(page.Drips), 0)
+}
+
+func TestApiImageFilters(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	total := func(url string) int {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+
+		var page ApiDripList
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		return page.Total
+	}
+
+	assert(t, total("/api/v1/drips?blank=true")+total("/api/v1/drips?blank=false"), 3)
+	assert(t, total("/api/v1/drips?hasRed=true")+total("/api/v1/drips?hasRed=false"), 3)
+	assert(t, total("/api/v1/drips?color=%23000001"), 0)
+	assert(t, total("/api/v1/drips?displayState=active,blank,test,off"), 3)
+	assert(t, total("/api/v1/drips?displayState=active")+total("/api/v1/drips?displayState=blank,test,off"), 3)
+
+	for _, url := range []string{"/api/v1/drips?blank=maybe", "/api/v1/drips?color=red", "/api/v1/drips?displayState=broken"} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert(t, recorder.Code, 400)
+	}
+}
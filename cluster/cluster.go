@@ -0,0 +1,79 @@
+// Package cluster groups map points that would overlap at a given zoom level
+package cluster
+
+import (
+	"math"
+	"sort"
+)
+
+// Size of a map tile in pixels, as used by Leaflet and most web maps
+const TileSize = 256
+
+type Point struct {
+	Id  string
+	Lat float64
+	Lon float64
+}
+
+type Cluster struct {
+	// Mean position of the points in the cluster
+	Lat    float64
+	Lon    float64
+	Points []Point
+}
+
+// Projects a position to Web Mercator pixel coordinates at the given zoom
+func Project(lat, lon float64, zoom int) (x, y float64) {
+	scale := TileSize * math.Exp2(float64(zoom))
+	sin := math.Sin(lat * math.Pi / 180)
+
+	x = (lon + 180) / 360 * scale
+	y = (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * scale
+
+	return x, y
+}
+
+type cell struct {
+	x, y int
+}
+
+// Groups points into square grid cells of radius*2 pixels at the given zoom
+// The grid is global, so the same zoom always produces the same clusters regardless of viewport
+// Clusters are ordered by descending size, then by the id of their first point
+func Grid(points []Point, zoom int, radius float64) []Cluster {
+	size := radius * 2
+	cells := make(map[cell]*Cluster)
+
+	for _, point := range points {
+		x, y := Project(point.Lat, point.Lon, zoom)
+		key := cell{int(math.Floor(x / size)), int(math.Floor(y / size))}
+
+		c, found := cells[key]
+		if !found {
+			c = &Cluster{}
+			cells[key] = c
+		}
+		c.Points = append(c.Points, point)
+	}
+
+	out := make([]Cluster, 0, len(cells))
+	for _, c := range cells {
+		for _, point := range c.Points {
+			c.Lat += point.Lat
+			c.Lon += point.Lon
+		}
+		c.Lat /= float64(len(c.Points))
+		c.Lon /= float64(len(c.Points))
+
+		out = append(out, *c)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].Points) != len(out[j].Points) {
+			return len(out[i].Points) > len(out[j].Points)
+		}
+		return out[i].Points[0].Id < out[j].Points[0].Id
+	})
+
+	return out
+}
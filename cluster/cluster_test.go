@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	x, y := Project(0, 0, 0)
+	if x != 128 || math.Abs(y-128) > 1e-9 {
+		t.Errorf("Expected the center of the world tile, got %v, %v", x, y)
+	}
+
+	x, _ = Project(52, 4, 1)
+	if x <= 256 || x >= 512 {
+		t.Errorf("Expected the Netherlands on the eastern half at zoom 1, got %v", x)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	points := []Point{
+		{Id: "UTRECHT_1", Lat: 52.09, Lon: 5.12},
+		{Id: "UTRECHT_2", Lat: 52.091, Lon: 5.121},
+		{Id: "GRONINGEN", Lat: 53.22, Lon: 6.57},
+	}
+
+	clusters := Grid(points, 7, 40)
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters at zoom 7, got %v", len(clusters))
+	}
+
+	if len(clusters[0].Points) != 2 || math.Abs(clusters[0].Lat-52.0905) > 1e-9 {
+		t.Errorf("Expected both Utrecht points in the first cluster, got %+v", clusters[0])
+	}
+
+	if clusters := Grid(points, 18, 40); len(clusters) != 3 {
+		t.Errorf("Expected every point on its own at zoom 18, got %v clusters", len(clusters))
+	}
+}
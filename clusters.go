@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hunternl/trafficmap/cluster"
+)
+
+type ClusterSummary struct {
+	// Centroid of the DRIPs in the cluster
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Count      int     `json:"count"`
+	Working    int     `json:"working"`
+	NonWorking int     `json:"nonWorking"`
+	WithImage  int     `json:"withImage"`
+	// Only set for clusters of a single DRIP
+	DripId string `json:"dripId,omitempty"`
+}
+
+// Area of the map as min lon, min lat, max lon, max lat
+type boundingBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+func (b boundingBox) contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// Parses a bbox parameter in the Leaflet toBBoxString() format: "west,south,east,north"
+func parseBoundingBox(str string) (boundingBox, error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != 4 {
+		return boundingBox{}, fmt.Errorf("bbox should be west,south,east,north")
+	}
+
+	nums := make([]float64, 4)
+	for i, part := range parts {
+		num, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return boundingBox{}, fmt.Errorf("bbox should be west,south,east,north")
+		}
+		nums[i] = num
+	}
+
+	box := boundingBox{MinLon: nums[0], MinLat: nums[1], MaxLon: nums[2], MaxLat: nums[3]}
+	if box.MinLon > box.MaxLon || box.MinLat > box.MaxLat {
+		return boundingBox{}, fmt.Errorf("bbox should be west,south,east,north")
+	}
+
+	return box, nil
+}
+
+// Clusters all DRIPs with a position at the given zoom level
+func summarizeClusters(drips []Drip, zoom int) []ClusterSummary {
+	byId := make(map[string]Drip, len(drips))
+	for _, drip := range drips {
+		byId[drip.Id] = drip
+	}
+
+	clusters := cluster.Grid(clusterPoints(drips), zoom, clusterRadius)
+	out := make([]ClusterSummary, len(clusters))
+
+	for i, c := range clusters {
+		summary := ClusterSummary{Lat: c.Lat, Lon: c.Lon, Count: len(c.Points)}
+
+		for _, point := range c.Points {
+			drip := byId[point.Id]
+			if drip.Working {
+				summary.Working++
+			} else {
+				summary.NonWorking++
+			}
+			if drip.hasImage() {
+				summary.WithImage++
+			}
+		}
+
+		if summary.Count == 1 {
+			summary.DripId = c.Points[0].Id
+		}
+
+		out[i] = summary
+	}
+
+	return out
+}
+
+// Serves /clusters?zoom=8&bbox=3.3,50.7,7.3,53.6
+// Clusters are computed over the whole country so they don't shift while panning, then limited to the bbox
+func handleClusters(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zoom, err := intParam(r, "zoom", -1)
+		if err != nil || zoom < 0 || zoom > maxTileZoom {
+			writeApiError(w, 400, fmt.Sprintf("zoom should be an integer from 0 to %v", maxTileZoom))
+			return
+		}
+
+		box := boundingBox{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}
+		if str := r.URL.Query().Get("bbox"); str != "" {
+			box, err = parseBoundingBox(str)
+			if err != nil {
+				writeApiError(w, 400, err.Error())
+				return
+			}
+		}
+
+		serv.Lock()
+		clusters := summarizeClusters(serv.DripsSlice, zoom)
+		serv.Unlock()
+
+		out := make([]ClusterSummary, 0, len(clusters))
+		for _, c := range clusters {
+			if box.contains(c.Lat, c.Lon) {
+				out = append(out, c)
+			}
+		}
+
+		writeJson(w, out)
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSummarizeClusters(t *testing.T) {
+	serv := newTestServ(t)
+
+	clusters := summarizeClusters(serv.DripsSlice, 0)
+	assert(t, len(clusters), 1)
+	assert(t, clusters[0].Count, 3)
+	assert(t, clusters[0].Working+clusters[0].NonWorking, 3)
+	assert(t, clusters[0].DripId, "")
+
+	clusters = summarizeClusters(serv.DripsSlice, 16)
+	assert(t, len(clusters), 3)
+	for _, c := range clusters {
+		assert(t, c.Count, 1)
+		if c.DripId == "" {
+			t.Errorf("Expected single DRIP clusters to name the DRIP")
+		}
+	}
+}
+
+func TestHandleClusters(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	tests := []struct {
+		url      string
+		status   int
+		clusters int
+	}{
+		{"/clusters?zoom=16", 200, 3},
+		{"/clusters?zoom=16&bbox=4.1,52.0,4.3,52.2", 200, 1},
+		{"/clusters?zoom=0&bbox=4.1,52.0,4.3,52.2", 200, 0},
+		{"/clusters", 400, 0},
+		{"/clusters?zoom=16&bbox=4.3,52.0,4.1,52.2", 400, 0},
+		{"/clusters?zoom=16&bbox=4.1,52.0", 400, 0},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", tt.url, nil))
+
+		if recorder.Code != tt.status {
+			t.Errorf("%v: expected status %v, got %v", tt.url, tt.status, recorder.Code)
+			continue
+		}
+		if tt.status != 200 {
+			continue
+		}
+
+		var clusters []ClusterSummary
+		if err := json.Unmarshal(recorder.Body.Bytes(), &clusters); err != nil {
+			t.Fatal(err)
+		}
+		if len(clusters) != tt.clusters {
+			t.Errorf("%v: expected %v clusters, got %v", tt.url, tt.clusters, len(clusters))
+		}
+	}
+}
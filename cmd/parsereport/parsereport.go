@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hunternl/trafficmap/description"
+	"github.com/hunternl/trafficmap/ndw"
+)
+
+// Locations sorted by id, so reports are stable between runs
+func sortedLocations(locations ndw.LocationMap) []ndw.Location {
+	out := make([]ndw.Location, 0, len(locations))
+	for _, location := range locations {
+		out = append(out, location)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Id < out[j].Id })
+	return out
+}
+
+func printCounts(title string, counts map[string]int, total int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Println(title)
+	for _, k := range keys {
+		fmt.Printf("  %-14v %6v (%.1f%%)\n", k, counts[k], float64(counts[k])*100/float64(total))
+	}
+	fmt.Println()
+}
+
+const corpusHeader = `# Snapshot of DRIP location descriptions, one per line
+# Refresh with: go run ./cmd/parsereport -corpus description/testdata/corpus.txt
+# Then accept the new parses with: go test ./description -run TestCorpus -update
+`
+
+// Writes every distinct description to fileName, for use as the description test corpus
+func writeCorpus(fileName string, records []ndw.Location) (int, error) {
+	seen := make(map[string]bool, len(records))
+	descriptions := make([]string, 0, len(records))
+
+	for _, record := range records {
+		if strings.TrimSpace(record.Description) == "" || strings.Contains(record.Description, "\n") || seen[record.Description] {
+			continue
+		}
+		seen[record.Description] = true
+		descriptions = append(descriptions, record.Description)
+	}
+
+	sort.Strings(descriptions)
+
+	return len(descriptions), os.WriteFile(fileName, []byte(corpusHeader+strings.Join(descriptions, "\n")+"\n"), 0644)
+}
+
+func main() {
+	sourceUrl := flag.String("sourceURL", "http://opendata.ndw.nu/", "Full URL to retrieve the location table from")
+	field := flag.String("missing", "", "List every description missing this field, like RoadId or Organization")
+	minConfidence := flag.String("below", "", "List every description with a confidence below this level (low, medium, high)")
+	corpusFile := flag.String("corpus", "", "Write the descriptions to this file as a test corpus instead of reporting")
+
+	flag.Parse()
+
+	locations, err := ndw.FetchLocations(*sourceUrl)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error retrieving location table:", err)
+		os.Exit(1)
+	}
+	records := sortedLocations(locations)
+
+	if *corpusFile != "" {
+		count, err := writeCorpus(*corpusFile, records)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing corpus:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Written %v descriptions to %v\n", count, *corpusFile)
+		return
+	}
+
+	levels := map[description.Confidence]int{
+		description.ConfidenceNone:   0,
+		description.ConfidenceLow:    1,
+		description.ConfidenceMedium: 2,
+		description.ConfidenceHigh:   3,
+	}
+
+	missing := make(map[string]int)
+	confidence := make(map[string]int)
+	withLeftover := 0
+
+	for _, record := range records {
+		_, trace := description.ParseWithTrace(record.Description)
+
+		confidence[string(trace.Confidence)]++
+		for _, f := range trace.Missing() {
+			missing[f]++
+
+			if f == *field {
+				fmt.Printf("%v\t%q\n", record.Id, record.Description)
+			}
+		}
+
+		if len(trace.Leftover) > 0 {
+			withLeftover++
+		}
+
+		if level, found := levels[description.Confidence(*minConfidence)]; found && levels[trace.Confidence] < level {
+			fmt.Printf("%v\t%v\t%q\tleftover: %q\n", record.Id, trace.Confidence, record.Description, trace.Leftover)
+		}
+	}
+
+	if *field != "" || *minConfidence != "" {
+		fmt.Println()
+	}
+
+	fmt.Printf("Parsed %v descriptions from %v\n\n", len(records), *sourceUrl)
+	printCounts("Missing fields:", missing, len(records))
+	printCounts("Confidence:", confidence, len(records))
+	fmt.Printf("Descriptions with leftover tokens: %v\n", withLeftover)
+}
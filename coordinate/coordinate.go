@@ -0,0 +1,72 @@
+// Conversion between WGS84 and Rijksdriehoek (RD New, EPSG:28992) coordinates
+// Uses the polynomial approximation by Schreutelaar, accurate to about a meter within the Netherlands
+package coordinate
+
+// Reference point, the Onze Lieve Vrouwetoren in Amersfoort
+const (
+	refX   = 155000.0
+	refY   = 463000.0
+	refLat = 52.15517440
+	refLon = 5.38720621
+)
+
+// Coefficient for dx^p * dy^q
+type term struct {
+	p, q int
+	c    float64
+}
+
+var latTerms = []term{
+	{0, 1, 3235.65389}, {2, 0, -32.58297}, {0, 2, -0.24750}, {2, 1, -0.84978},
+	{0, 3, -0.06550}, {2, 2, -0.01709}, {1, 0, -0.00738}, {4, 0, 0.00530},
+	{2, 3, -0.00039}, {4, 1, 0.00033}, {1, 1, -0.00012},
+}
+
+var lonTerms = []term{
+	{1, 0, 5260.52916}, {1, 1, 105.94684}, {1, 2, 2.45656}, {3, 0, -0.81885},
+	{1, 3, 0.05594}, {3, 1, -0.05607}, {0, 1, 0.01199}, {3, 2, -0.00256},
+	{1, 4, 0.00128}, {0, 2, 0.00022}, {2, 0, -0.00022}, {5, 0, 0.00026},
+}
+
+var xTerms = []term{
+	{0, 1, 190094.945}, {1, 1, -11832.228}, {2, 1, -114.221}, {0, 3, -32.391},
+	{1, 0, -0.705}, {3, 1, -2.340}, {1, 3, -0.608}, {0, 2, -0.008}, {2, 3, 0.148},
+}
+
+var yTerms = []term{
+	{1, 0, 309056.544}, {0, 2, 3638.893}, {2, 0, 73.077}, {1, 2, -157.984},
+	{3, 0, 59.788}, {0, 1, 0.433}, {2, 2, -6.439}, {1, 1, -0.032},
+	{0, 4, 0.092}, {1, 4, -0.054},
+}
+
+func pow(base float64, exp int) float64 {
+	out := 1.0
+	for i := 0; i < exp; i++ {
+		out *= base
+	}
+	return out
+}
+
+func sum(terms []term, a, b float64) float64 {
+	total := 0.0
+	for _, t := range terms {
+		total += t.c * pow(a, t.p) * pow(b, t.q)
+	}
+	return total
+}
+
+// Converts WGS84 latitude and longitude in degrees to RD x and y in meters
+func WGS84ToRD(lat, lon float64) (x, y float64) {
+	dLat := 0.36 * (lat - refLat)
+	dLon := 0.36 * (lon - refLon)
+
+	return refX + sum(xTerms, dLat, dLon), refY + sum(yTerms, dLat, dLon)
+}
+
+// Converts RD x and y in meters to WGS84 latitude and longitude in degrees
+func RDToWGS84(x, y float64) (lat, lon float64) {
+	dX := (x - refX) * 1e-5
+	dY := (y - refY) * 1e-5
+
+	return refLat + sum(latTerms, dX, dY)/3600, refLon + sum(lonTerms, dX, dY)/3600
+}
@@ -0,0 +1,100 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestReferencePoint(t *testing.T) {
+	x, y := WGS84ToRD(refLat, refLon)
+	if !near(x, refX, 0.001) || !near(y, refY, 0.001) {
+		t.Errorf("Expected reference point to map to (%v, %v), got (%v, %v)", refX, refY, x, y)
+	}
+
+	lat, lon := RDToWGS84(refX, refY)
+	if !near(lat, refLat, 1e-9) || !near(lon, refLon, 1e-9) {
+		t.Errorf("Expected reference point to map to (%v, %v), got (%v, %v)", refLat, refLon, lat, lon)
+	}
+}
+
+func TestKnownPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		x, y     float64
+	}{
+		// Martinitoren, Groningen
+		{"Groningen", 53.21917, 6.56833, 233883, 582065},
+		// Vrijthof, Maastricht
+		{"Maastricht", 50.84869, 5.68939, 176263, 317719},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := WGS84ToRD(tt.lat, tt.lon)
+			if !near(x, tt.x, 25) || !near(y, tt.y, 25) {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.x, tt.y, x, y)
+			}
+
+			lat, lon := RDToWGS84(x, y)
+			if !near(lat, tt.lat, 1e-5) || !near(lon, tt.lon, 1e-5) {
+				t.Errorf("Round trip gave (%v, %v) instead of (%v, %v)", lat, lon, tt.lat, tt.lon)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		lat, lon string
+		want     Status
+	}{
+		{"52.1", "4.2", StatusValid},
+		{" 53.21917", "6.56833 ", StatusValid},
+		{"", "4.2", StatusInvalid},
+		{"52,1", "4,2", StatusInvalid},
+		{"NaN", "4.2", StatusInvalid},
+		{"152.1", "4.2", StatusInvalid},
+		{"0", "0", StatusOutside},
+		{"48.85", "2.35", StatusOutside},
+	}
+
+	for _, tt := range tests {
+		if _, _, got := Parse(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, expected %v", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestParseCRS(t *testing.T) {
+	for _, name := range []string{"rd", "RD", "28992", "EPSG:28992", " epsg:28992 "} {
+		if crs, err := ParseCRS(name); err != nil || crs != RD {
+			t.Errorf("ParseCRS(%q) = %v, %v, want RD", name, crs, err)
+		}
+	}
+
+	for _, name := range []string{"wgs84", "WGS84", "4326", "EPSG:4326"} {
+		if crs, err := ParseCRS(name); err != nil || crs != WGS84 {
+			t.Errorf("ParseCRS(%q) = %v, %v, want WGS84", name, crs, err)
+		}
+	}
+
+	for _, name := range []string{"", "3857", "mercator"} {
+		if _, err := ParseCRS(name); err == nil {
+			t.Errorf("ParseCRS(%q) should fail", name)
+		}
+	}
+
+	if x, y := WGS84.FromWGS84(52.1, 4.2); x != 4.2 || y != 52.1 {
+		t.Errorf("WGS84 should give longitude, latitude, got %v, %v", x, y)
+	}
+
+	x, y := RD.FromWGS84(refLat, refLon)
+	if !near(x, refX, 0.01) || !near(y, refY, 0.01) {
+		t.Errorf("RD of the reference point should be %v, %v, got %v, %v", refX, refY, x, y)
+	}
+}
@@ -0,0 +1,41 @@
+package coordinate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Coordinate reference system an export is written in, by EPSG code
+type CRS string
+
+const (
+	WGS84 CRS = "EPSG:4326"
+	// Rijksdriehoek (RD New), in meters
+	RD CRS = "EPSG:28992"
+)
+
+var crsNames = map[string]CRS{
+	"wgs84":      WGS84,
+	"4326":       WGS84,
+	"epsg:4326":  WGS84,
+	"rd":         RD,
+	"28992":      RD,
+	"epsg:28992": RD,
+}
+
+// Parses a CRS by name (wgs84, rd) or EPSG code, ignoring case
+func ParseCRS(str string) (CRS, error) {
+	crs, found := crsNames[strings.ToLower(strings.TrimSpace(str))]
+	if !found {
+		return "", fmt.Errorf("unknown CRS %q, expected wgs84 (EPSG:4326) or rd (EPSG:28992)", str)
+	}
+	return crs, nil
+}
+
+// Converts a WGS84 position to x and y in this CRS, for WGS84 that's longitude and latitude
+func (c CRS) FromWGS84(lat, lon float64) (x, y float64) {
+	if c == RD {
+		return WGS84ToRD(lat, lon)
+	}
+	return lon, lat
+}
@@ -0,0 +1,45 @@
+package coordinate
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Status string
+
+const (
+	StatusValid Status = "valid"
+	// Could not be parsed, or is not a position on earth
+	StatusInvalid Status = "invalid"
+	// A real position, but too far from the Netherlands to be a DRIP
+	StatusOutside Status = "outside"
+)
+
+// Generous bounding box around the Netherlands, including border areas and coastal waters
+const (
+	minLat = 50.6
+	maxLat = 53.7
+	minLon = 3.2
+	maxLon = 7.3
+)
+
+func InNetherlands(lat, lon float64) bool {
+	return lat >= minLat && lat <= maxLat && lon >= minLon && lon <= maxLon
+}
+
+// Parses WGS84 latitude and longitude strings as found in the location table
+func Parse(latStr, lonStr string) (lat float64, lon float64, status Status) {
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+
+	if latErr != nil || lonErr != nil || math.IsNaN(lat) || math.IsNaN(lon) || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return 0, 0, StatusInvalid
+	}
+
+	if !InNetherlands(lat, lon) {
+		return lat, lon, StatusOutside
+	}
+
+	return lat, lon, StatusValid
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CorridorDrip struct {
+	Drip
+	// Meters driven from the origin until passing this DRIP
+	Distance int `json:"distance"`
+}
+
+type Corridor struct {
+	RoadId   string         `json:"roadId"`
+	RoadSide string         `json:"roadSide"`
+	From     int            `json:"from"`
+	To       int            `json:"to"`
+	Drips    []CorridorDrip `json:"drips"`
+}
+
+var sideParams = map[string]string{
+	"":       "",
+	"l":      "L",
+	"li":     "L",
+	"links":  "L",
+	"r":      "R",
+	"re":     "R",
+	"rechts": "R",
+}
+
+// Largest hectometer position accepted, in kilometers, the same limit as road offsets in descriptions
+// Checked before converting to meters, as larger values overflow int
+const maxHectometer = 1_000
+
+// Parses a hectometer position in kilometers like "40.0" or "40,0" into meters
+func parseHectometer(str string) (int, error) {
+	num, err := strconv.ParseFloat(strings.ReplaceAll(str, ",", "."), 64)
+	if err != nil || math.IsNaN(num) || num < 0 || num > maxHectometer {
+		return -1, fmt.Errorf("invalid hectometer position %q, expected 0 to %v km", str, maxHectometer)
+	}
+
+	return int(num*1000 + 0.5), nil
+}
+
+// Hectometers count up when driving on the right side (Re) and down on the left side (Li)
+// so the direction of travel decides which side a driver is on
+func sideForDirection(from, to int) string {
+	if from <= to {
+		return "R"
+	}
+	return "L"
+}
+
+// Lists the DRIPs a driver passes going from one offset to another, in the order they are passed
+func corridor(drips []Drip, roadId, side string, from, to int) (Corridor, error) {
+	expectedSide := sideForDirection(from, to)
+	if side == "" {
+		side = expectedSide
+	} else if side != expectedSide {
+		return Corridor{}, fmt.Errorf("driving from %v to %v means driving on side %v, not %v", from, to, expectedSide, side)
+	}
+
+	out := Corridor{
+		RoadId:   roadId,
+		RoadSide: side,
+		From:     from,
+		To:       to,
+		Drips:    make([]CorridorDrip, 0),
+	}
+
+	detail, found := roadDetail(drips, roadId)
+	if !found {
+		return out, nil
+	}
+
+	low, high := from, to
+	if low > high {
+		low, high = high, low
+	}
+
+	// Sides are sorted by ascending offset, walk them backwards on the left side
+	sideDrips := detail.Sides[side]
+	for i := range sideDrips {
+		drip := sideDrips[i]
+		if side == "L" {
+			drip = sideDrips[len(sideDrips)-1-i]
+		}
+
+		if drip.RoadOffset < low || drip.RoadOffset > high {
+			continue
+		}
+
+		distance := drip.RoadOffset - from
+		if distance < 0 {
+			distance = -distance
+		}
+
+		out.Drips = append(out.Drips, CorridorDrip{Drip: drip, Distance: distance})
+	}
+
+	return out, nil
+}
+
+// Serves /corridor?road=A2&from=40.0&to=80.0, optionally with &side=R to check the direction
+func handleCorridor(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		roadId := strings.ToUpper(query.Get("road"))
+		side, validSide := sideParams[strings.ToLower(query.Get("side"))]
+
+		from, fromErr := parseHectometer(query.Get("from"))
+		to, toErr := parseHectometer(query.Get("to"))
+
+		if roadId == "" || query.Get("from") == "" || query.Get("to") == "" {
+			http.Error(w, "expected road, from and to parameters, like ?road=A2&from=40.0&to=80.0", 400)
+			return
+		}
+
+		for _, err := range []error{fromErr, toErr} {
+			if err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+
+		if !validSide {
+			http.Error(w, "side should be L (Li) or R (Re)", 400)
+			return
+		}
+
+		serv.Lock()
+		result, err := corridor(serv.DripsSlice, roadId, side, from, to)
+		serv.Unlock()
+
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		str, err := json.Marshal(result)
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(str)
+	})
+}
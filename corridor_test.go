@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCorridor(t *testing.T) {
+	drips := []Drip{
+		{Id: "R1", RoadId: "A2", RoadSide: "R", RoadOffset: 45000},
+		{Id: "R2", RoadId: "A2", RoadSide: "R", RoadOffset: 38000},
+		{Id: "R3", RoadId: "A2", RoadSide: "R", RoadOffset: 62000},
+		{Id: "L1", RoadId: "A2", RoadSide: "L", RoadOffset: 50000},
+		{Id: "L2", RoadId: "A2", RoadSide: "L", RoadOffset: 70000},
+		{Id: "L3", RoadId: "A2", RoadSide: "L", RoadOffset: -1},
+	}
+
+	right, err := corridor(drips, "A2", "", 40000, 80000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, right.RoadSide, "R")
+	assert(t, len(right.Drips), 2)
+	assert(t, right.Drips[0].Id, "R1")
+	assert(t, right.Drips[0].Distance, 5000)
+	assert(t, right.Drips[1].Id, "R3")
+
+	left, err := corridor(drips, "A2", "L", 80000, 40000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, len(left.Drips), 2)
+	assert(t, left.Drips[0].Id, "L2")
+	assert(t, left.Drips[0].Distance, 10000)
+	assert(t, left.Drips[1].Id, "L1")
+
+	if _, err := corridor(drips, "A2", "L", 40000, 80000); err == nil {
+		t.Error("Expected an error when side and direction disagree")
+	}
+}
+
+func TestParseHectometer(t *testing.T) {
+	cases := map[string]int{
+		"40.0":   40000,
+		"40,0":   40000,
+		"12.7":   12700,
+		"0":      0,
+		"1000":   1000000,
+		"1000.1": -1,
+		"1e300":  -1,
+		"-1":     -1,
+		"NaN":    -1,
+		"Inf":    -1,
+		"":       -1,
+	}
+
+	for str, want := range cases {
+		got, err := parseHectometer(str)
+		if want == -1 && err == nil {
+			t.Errorf("%q: expected an error, got %v", str, got)
+		}
+		if want != -1 && (err != nil || got != want) {
+			t.Errorf("%q: expected %v, got %v, %v", str, want, got, err)
+		}
+	}
+}
+
+func TestCorridorParams(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	cases := map[string]string{
+		"/corridor?road=A2&from=40.0":            "expected road, from and to parameters",
+		"/corridor?road=A2&from=1e300&to=80.0":   "expected 0 to 1000 km",
+		"/corridor?road=A2&from=40.0&to=-5":      "expected 0 to 1000 km",
+		"/corridor?road=A2&from=40&to=80&side=X": "side should be L (Li) or R (Re)",
+	}
+
+	for url, message := range cases {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+
+		assert(t, recorder.Code, 400)
+		if !strings.Contains(recorder.Body.String(), message) {
+			t.Errorf("%v: expected %q, got %q", url, message, recorder.Body.String())
+		}
+	}
+}
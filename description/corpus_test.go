@@ -0,0 +1,123 @@
+package description
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var updateCorpus = flag.Bool("update", false, "Rewrite testdata/corpus.golden.json with the current parse results")
+
+const corpusFile = "testdata/corpus.txt"
+const goldenFile = "testdata/corpus.golden.json"
+
+type corpusEntry struct {
+	Description string                 `json:"description"`
+	Want        DescriptionDerivatives `json:"want"`
+}
+
+func readCorpus(t *testing.T) []string {
+	t.Helper()
+
+	file, err := os.Open(corpusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	descriptions := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		descriptions = append(descriptions, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return descriptions
+}
+
+// Lists every field that differs between two parses as "Field: old -> new"
+func diffDerivatives(old, new DescriptionDerivatives) []string {
+	out := make([]string, 0)
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+
+	for i := 0; i < oldValue.NumField(); i++ {
+		a, b := oldValue.Field(i).Interface(), newValue.Field(i).Interface()
+		if !reflect.DeepEqual(a, b) {
+			out = append(out, fmt.Sprintf("%v: %q -> %q", oldValue.Type().Field(i).Name, fmt.Sprint(a), fmt.Sprint(b)))
+		}
+	}
+
+	return out
+}
+
+// Parses every description in the corpus and compares it to the golden file
+// Run with -update to accept changes, the diff report is logged either way
+func TestCorpus(t *testing.T) {
+	descriptions := readCorpus(t)
+
+	golden := make(map[string]DescriptionDerivatives)
+	if data, err := os.ReadFile(goldenFile); err == nil {
+		var entries []corpusEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			golden[entry.Description] = entry.Want
+		}
+	} else if !*updateCorpus {
+		t.Fatalf("Error reading golden file, run with -update to create it: %v", err)
+	}
+
+	report := strings.Builder{}
+	changed, added := 0, 0
+	entries := make([]corpusEntry, len(descriptions))
+
+	for i, description := range descriptions {
+		got := Parse(description)
+		entries[i] = corpusEntry{Description: description, Want: got}
+
+		want, found := golden[description]
+		if !found {
+			added++
+			fmt.Fprintf(&report, "+ %q\n", description)
+			continue
+		}
+
+		if diff := diffDerivatives(want, got); len(diff) > 0 {
+			changed++
+			fmt.Fprintf(&report, "~ %q\n    %v\n", description, strings.Join(diff, "\n    "))
+		}
+	}
+
+	if report.Len() > 0 {
+		t.Logf("%v of %v parses changed, %v new:\n%v", changed, len(descriptions), added, report.String())
+	}
+
+	if *updateCorpus {
+		data, err := json.MarshalIndent(entries, "", "\t")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(goldenFile, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	if changed > 0 || added > 0 {
+		t.Errorf("Corpus parses differ from %v, run with -update to accept them", goldenFile)
+	}
+}
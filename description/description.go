@@ -1,6 +1,7 @@
 package description
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -16,16 +17,15 @@ func init() {
 }
 
 type DescriptionDerivatives struct {
-	Organization string
-	RoadId       string
-	RoadOffset   int
-	RoadSide     string
-	Name         string
-}
-
-var orgMap = map[string]string{
-	"PZH": "Provincie Zuid-Holland",
-	"GDH": "Gemeente Den Haag",
+	Organization     string
+	OrganizationCode string
+	RoadId           string
+	RoadOffset       int
+	RoadSide         string
+	Carriageway      Carriageway
+	Junction         string
+	HectometerLetter string
+	Name             string
 }
 
 var blackList = map[string]bool{
@@ -58,33 +58,58 @@ func sideSuffix(str string) (string, bool) {
 	return "", false
 }
 
+// A road type prefix followed by digits, optionally ending in a roadSide suffix, like "N223L"
+// A lone prefix is not a road, "A" and "N" also appear as plain words
 func isRoadId(str string) bool {
-	for i, r := range str {
-		// First rune must be a road type prefix
-		if i == 0 {
-			if _, found := roadPrefixes[r]; !found {
-				return false
-			}
-		} else {
-			// Allow final rune to be a roadSide suffix
-			if i == len(str)-1 {
-				if _, hasSuffix := sideSuffix(str); hasSuffix {
-					return true
-				}
-			}
-			if !unicode.IsDigit(r) {
-				return false
-			}
+	if len(str) < 2 || !roadPrefixes[rune(str[0])] {
+		return false
+	}
+
+	digits := str[1:]
+	if _, hasSuffix := sideSuffix(str); hasSuffix {
+		digits = digits[:len(digits)-1]
+	}
+
+	return digits != "" && strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) == -1
+}
+
+// Road numbers are written with and without leading zeros, like "A016" and "A16"
+func normalizeRoadId(str string) string {
+	if len(str) < 2 {
+		return str
+	}
+	digits := strings.TrimLeft(str[1:], "0")
+	if digits == "" {
+		digits = "0"
+	}
+	return str[:1] + digits
+}
+
+// Finds a road number mentioned in a name, like the N194 in "Westfrisiaweg N194 Hoorn"
+// Only a prefix followed by digits counts, so words like "A" or "Noord" are skipped
+func roadIdInName(name string) (string, bool) {
+	for _, field := range strings.Fields(name) {
+		if len(field) < 2 || !roadPrefixes[rune(field[0])] {
+			continue
+		}
+		if strings.IndexFunc(field[1:], func(r rune) bool { return r < '0' || r > '9' }) == -1 {
+			return normalizeRoadId(field), true
 		}
 	}
-	return true
+	return "", false
 }
 
+// Largest offset accepted in a description, in kilometers as written there
+// Well beyond the length of any road, RoadOffset itself is stored in meters
+const maxRoadOffset = 1_000
+
 func parseRoadOffset(str string) (int, bool) {
+	str, _ = splitHectometerLetter(str)
 	str = strings.ReplaceAll(strings.TrimRight(str, "km"), ",", ".")
 	num, err := strconv.ParseFloat(str, 64)
 
-	if err != nil {
+	// Road offsets are never negative or longer than any road
+	if err != nil || math.IsNaN(num) || num < 0 || num > maxRoadOffset {
 		return -1, false
 	}
 
@@ -93,7 +118,9 @@ func parseRoadOffset(str string) (int, bool) {
 
 // Takes a string, returning bits of road data it can find
 // Reads the string from left to right, outputing remaining "uninteresting" string as `description`
-func parseRoadData(in string) (roadId string, roadOffset int, roadSide string, description string) {
+// The rule used for every field found is recorded in trace
+// A hectometer letter is only taken from the offset, like the "a" in "12,3a"
+func parseRoadData(in string, trace *Trace) (roadId string, roadOffset int, roadSide string, hectometerLetter string, description string) {
 	roadOffset = -1
 	description = in
 	remainingBits := strings.FieldsFunc(in, func(r rune) bool {
@@ -110,17 +137,21 @@ func parseRoadData(in string) (roadId string, roadOffset int, roadSide string, d
 	for i, field := range remainingBits {
 		if roadId == "" && isRoadId(field) {
 			roadId = field
+			trace.Rules["RoadId"] = RuleRoadId
 
 			if side, hasSuffix := sideSuffix(roadId); hasSuffix {
 				roadSide = side
 				roadId = roadId[:len(roadId)-1]
+				trace.Rules["RoadSide"] = RuleSideSuffix
 			}
+			roadId = normalizeRoadId(roadId)
 			continue
 		}
 
 		if roadSide == "" {
 			if side, isSide := sideLookup[strings.ToLower(field)]; isSide {
 				roadSide = side
+				trace.Rules["RoadSide"] = RuleSideWord
 				continue
 			}
 		}
@@ -128,8 +159,10 @@ func parseRoadData(in string) (roadId string, roadOffset int, roadSide string, d
 		if roadSide == "" && roadId == "" {
 			matches := backupRegex.FindStringSubmatch(field)
 			if len(matches) == 3 {
-				roadId = matches[1]
+				roadId = normalizeRoadId(matches[1])
 				roadSide = matches[2]
+				trace.Rules["RoadId"] = RuleBackupRegex
+				trace.Rules["RoadSide"] = RuleBackupRegex
 
 				stringLoc := backupRegex.FindStringIndex(field)
 				field = field[stringLoc[1]:]
@@ -141,6 +174,12 @@ func parseRoadData(in string) (roadId string, roadOffset int, roadSide string, d
 			offset, isOffset := parseRoadOffset(field)
 			if isOffset {
 				roadOffset = offset
+				trace.Rules["RoadOffset"] = RuleOffset
+
+				if _, letter := splitHectometerLetter(field); letter != "" {
+					hectometerLetter = letter
+					trace.Rules["HectometerLetter"] = RuleHectometerLetter
+				}
 				continue
 			}
 		}
@@ -258,12 +297,20 @@ func parseRoadData(in string) (roadId string, roadOffset int, roadSide string, d
 // 	return
 // }
 
+// Derives organization, road data and a name from a DRIP location description
 func Parse(description string) DescriptionDerivatives {
+	out, _ := ParseWithTrace(description)
+	return out
+}
+
+// Like Parse, but also reports which rule produced each field and what was left over
+func ParseWithTrace(description string) (DescriptionDerivatives, Trace) {
+	trace := newTrace()
 	description = strings.TrimSpace(description)
 
 	// Remove parentheses and everything contained, starting from the right
 	var inParens = false
-	description = strings.TrimRightFunc(description, func(r rune) bool {
+	trimmed := strings.TrimRightFunc(description, func(r rune) bool {
 		if r == ')' {
 			inParens = true
 			return true
@@ -280,31 +327,53 @@ func Parse(description string) DescriptionDerivatives {
 
 		return false
 	})
+	trace.Leftover = append(trace.Leftover, strings.FieldsFunc(description[len(trimmed):], func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == ')'
+	})...)
+	description = trimmed
 
 	out := DescriptionDerivatives{}
 
-	// Remove and save organization prefix
+	// Remove organization prefix and look up who it belongs to
 	description = strings.TrimSpace(description)
-	if strings.Contains(description, " - ") {
-		if left, right, found := strings.Cut(description, " - "); found {
-			description = right
+	fullDescription := description
+	identifier := ""
+	if left, right, found := strings.Cut(description, " - "); found {
+		identifier = left
+		description = right
+	}
 
-			for prefix, fullName := range orgMap {
-				if len(left) >= 3 && (left[:3]) == prefix {
-					out.Organization = fullName
-				}
-			}
-		}
+	if org, rule, found := Organizations.lookup(identifier, fullDescription); found {
+		out.Organization = org.Name
+		out.OrganizationCode = org.Code
+		trace.Rules["Organization"] = rule
+	} else if identifier != "" {
+		trace.Leftover = append(trace.Leftover, identifier)
 	}
 
-	out.RoadId, out.RoadOffset, out.RoadSide, description = parseRoadData(description)
+	parsePosition(description, &out, &trace)
+	out.RoadId, out.RoadOffset, out.RoadSide, out.HectometerLetter, description = parseRoadData(description, &trace)
+
+	// The name is kept as is, the road number is part of how the location is known
+	if out.RoadId == "" {
+		if roadId, found := roadIdInName(description); found {
+			out.RoadId = roadId
+			trace.Rules["RoadId"] = RuleRoadIdInName
+		}
+	}
 
 	// Remove any leftover meaningless words
 	if blackList[description] {
+		trace.Leftover = append(trace.Leftover, description)
 		description = ""
 	}
 
+	if description != "" {
+		trace.Rules["Name"] = RuleRemainder
+	}
+
 	out.Name = description
+	trace.Confidence = trace.computeConfidence()
 
-	return out
+	return out, trace
 }
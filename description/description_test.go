@@ -38,11 +38,12 @@ func TestParseDescription(t *testing.T) {
 			name: "Parses organization names",
 			args: "PZH_DRIP65 - Hoefweg Veiling Bleiswijk",
 			want: DescriptionDerivatives{
-				Organization: "Provincie Zuid-Holland",
-				RoadId:       "",
-				RoadOffset:   -1,
-				RoadSide:     "",
-				Name:         "Hoefweg Veiling Bleiswijk",
+				Organization:     "Provincie Zuid-Holland",
+				OrganizationCode: "PZH",
+				RoadId:           "",
+				RoadOffset:       -1,
+				RoadSide:         "",
+				Name:             "Hoefweg Veiling Bleiswijk",
 			},
 		},
 		{
@@ -60,11 +61,12 @@ func TestParseDescription(t *testing.T) {
 			name: "Parses road id, side and offset",
 			args: "PZH_DRIP14 - N211 R 12.7 Poeldijk (9eff9e60-3ece-4abd-84cb-be319504e1)",
 			want: DescriptionDerivatives{
-				Organization: "Provincie Zuid-Holland",
-				RoadId:       "N211",
-				RoadOffset:   12700,
-				RoadSide:     "R",
-				Name:         "Poeldijk",
+				Organization:     "Provincie Zuid-Holland",
+				OrganizationCode: "PZH",
+				RoadId:           "N211",
+				RoadOffset:       12700,
+				RoadSide:         "R",
+				Name:             "Poeldijk",
 			},
 		},
 		{
@@ -104,11 +106,42 @@ func TestParseDescription(t *testing.T) {
 			name: "Handles m suffixes in road offset",
 			args: "GDH_QW-18-06 - A4 Re 44,570m parallelbaan voor A4/A12 knp Prins Clausplein (74c46760-51c7-4187-827d-d020dc112133)",
 			want: DescriptionDerivatives{
-				Organization: "Gemeente Den Haag",
-				RoadId:       "A4",
-				RoadOffset:   44570,
-				RoadSide:     "R",
-				Name:         "parallelbaan voor A4/A12 knp Prins Clausplein",
+				Organization:     "Gemeente Den Haag",
+				OrganizationCode: "GDH",
+				RoadId:           "A4",
+				RoadOffset:       44570,
+				RoadSide:         "R",
+				Carriageway:      CarriagewayParallel,
+				Junction:         "Prins Clausplein",
+				Name:             "parallelbaan voor A4/A12 knp Prins Clausplein",
+			},
+		},
+		{
+			name: "Ignores a road letter without a number",
+			args: "A km 12",
+			want: DescriptionDerivatives{
+				RoadOffset: -1,
+				Name:       "A km 12",
+			},
+		},
+		{
+			name: "Drops leading zeros from road ids",
+			args: "A016L69,900",
+			want: DescriptionDerivatives{
+				RoadId:     "A16",
+				RoadOffset: 69900,
+				RoadSide:   "L",
+			},
+		},
+		{
+			name: "Finds road ids in names",
+			args: "PNH_DRIP12 - Westfrisiaweg N194 Hoorn",
+			want: DescriptionDerivatives{
+				Organization:     "Provincie Noord-Holland",
+				OrganizationCode: "PNH",
+				RoadId:           "N194",
+				RoadOffset:       -1,
+				Name:             "Westfrisiaweg N194 Hoorn",
 			},
 		},
 		{
@@ -144,6 +177,40 @@ func TestParseDescription(t *testing.T) {
 				Name:         "",
 			},
 		},
+		{
+			name: "Parses hectometer letters and carriageways",
+			args: "A10 Re 23,1b afrit S108",
+			want: DescriptionDerivatives{
+				RoadId:           "A10",
+				RoadOffset:       23100,
+				RoadSide:         "R",
+				Carriageway:      CarriagewayExit,
+				HectometerLetter: "b",
+				Name:             "afrit S108",
+			},
+		},
+		{
+			name: "Only takes hectometer letters from the offset",
+			args: "A10 Li 12,3 richting afrit 14,5c",
+			want: DescriptionDerivatives{
+				RoadId:      "A10",
+				RoadOffset:  12300,
+				RoadSide:    "L",
+				Carriageway: CarriagewayExit,
+				Name:        "richting afrit 14,5c",
+			},
+		},
+		{
+			name: "Stops junction names at stop words",
+			args: "A2 Re 40,0 knp Oudenrijn richting Utrecht",
+			want: DescriptionDerivatives{
+				RoadId:     "A2",
+				RoadOffset: 40000,
+				RoadSide:   "R",
+				Junction:   "Oudenrijn",
+				Name:       "knp Oudenrijn richting Utrecht",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -155,3 +222,114 @@ func TestParseDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestOrganizationRegistry(t *testing.T) {
+	registry, err := LoadRegistry([]byte(`[
+		{"code": "PZH", "name": "Provincie Zuid-Holland", "prefixes": ["PZH"]},
+		{"code": "RWS", "name": "Rijkswaterstaat", "patterns": ["(?i)\\brijkswaterstaat\\b"]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if org, found := registry.Lookup("pzh_drip65", "pzh_drip65 - Hoefweg"); !found || org.Code != "PZH" {
+		t.Errorf("Expected prefix match on PZH, got %v", org)
+	}
+
+	if org, found := registry.Lookup("", "A12 Re 40,0 Rijkswaterstaat"); !found || org.Code != "RWS" {
+		t.Errorf("Expected pattern match on RWS, got %v", org)
+	}
+
+	if _, found := registry.Lookup("XYZ_1", "XYZ_1 - Somewhere"); found {
+		t.Errorf("Expected no match for unknown prefix")
+	}
+
+	err = registry.Override(OrganizationRule{Code: "PZH", Name: "Zuid-Holland", Prefixes: []string{"PZH", "ZH"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if org, _ := registry.Lookup("ZH_1", ""); org.Name != "Zuid-Holland" {
+		t.Errorf("Expected override to apply, got %v", org)
+	}
+
+	if len(registry.All()) != 2 {
+		t.Errorf("Expected override to replace the existing rule, got %v", registry.All())
+	}
+
+	if _, err := LoadRegistry([]byte(`[{"code": "X", "patterns": ["("]}]`)); err == nil {
+		t.Errorf("Expected invalid pattern to be rejected")
+	}
+}
+
+func TestParseWithTrace(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       string
+		rules      map[string]string
+		leftover   []string
+		confidence Confidence
+	}{
+		{
+			name: "Traces every rule",
+			args: "PZH_DRIP14 - N211 R 12.7 Poeldijk (9eff9e60-3ece-4abd-84cb-be319504e1)",
+			rules: map[string]string{
+				"Organization": RuleOrgPrefix,
+				"RoadId":       RuleRoadId,
+				"RoadSide":     RuleSideWord,
+				"RoadOffset":   RuleOffset,
+				"Name":         RuleRemainder,
+			},
+			leftover:   []string{"9eff9e60-3ece-4abd-84cb-be319504e1"},
+			confidence: ConfidenceHigh,
+		},
+		{
+			name: "Lowers confidence for fallback rules",
+			args: "A16L69,900",
+			rules: map[string]string{
+				"RoadId":     RuleBackupRegex,
+				"RoadSide":   RuleBackupRegex,
+				"RoadOffset": RuleOffset,
+			},
+			leftover:   []string{},
+			confidence: ConfidenceMedium,
+		},
+		{
+			name: "Reports unknown organizations and blacklisted words as leftover",
+			args: "201309 - Rechts",
+			rules: map[string]string{
+				"RoadSide": RuleSideWord,
+			},
+			leftover:   []string{"201309"},
+			confidence: ConfidenceMedium,
+		},
+		{
+			name:       "Gives low confidence to plain names",
+			args:       "Waterlandlaan",
+			rules:      map[string]string{"Name": RuleRemainder},
+			leftover:   []string{},
+			confidence: ConfidenceLow,
+		},
+		{
+			name:       "Gives no confidence to empty descriptions",
+			args:       "  ",
+			rules:      map[string]string{},
+			leftover:   []string{},
+			confidence: ConfidenceNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, trace := ParseWithTrace(tt.args)
+			if !reflect.DeepEqual(trace.Rules, tt.rules) {
+				t.Errorf("Expected rules %v, got %v", tt.rules, trace.Rules)
+			}
+			if !reflect.DeepEqual(trace.Leftover, tt.leftover) {
+				t.Errorf("Expected leftover %q, got %q", tt.leftover, trace.Leftover)
+			}
+			if trace.Confidence != tt.confidence {
+				t.Errorf("Expected confidence %v, got %v", tt.confidence, trace.Confidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package description
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Seeds a fuzz target with every description in the corpus
+func addCorpusSeeds(f *testing.F) {
+	data, err := os.ReadFile(corpusFile)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			f.Add(line)
+		}
+	}
+}
+
+// A road type prefix followed by digits without leading zeros
+var roadIdRegex = regexp.MustCompile(`^[ANS](0|[1-9][0-9]*)$`)
+
+// Reports whether in holds a token roadId can be read from, leading zeros are dropped from road ids
+func hasRoadToken(in, roadId string) bool {
+	token := regexp.MustCompile(regexp.QuoteMeta(roadId[:1]) + "0*" + regexp.QuoteMeta(roadId[1:]) + "([^0-9]|$)")
+	return token.MatchString(in)
+}
+
+func checkRoadData(t *testing.T, roadOffset int, roadSide string) {
+	t.Helper()
+
+	if roadOffset != -1 && roadOffset < 0 {
+		t.Errorf("RoadOffset should be -1 or non-negative, got %v", roadOffset)
+	}
+
+	if roadSide != "" && roadSide != "L" && roadSide != "R" {
+		t.Errorf("RoadSide should be empty, L or R, got %q", roadSide)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	addCorpusSeeds(f)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		out, trace := ParseWithTrace(in)
+		checkRoadData(t, out.RoadOffset, out.RoadSide)
+
+		if out.RoadId != "" && !roadIdRegex.MatchString(out.RoadId) {
+			t.Errorf("RoadId of %q should be a road prefix and digits, got %q", in, out.RoadId)
+		}
+		if out.RoadId != "" && !hasRoadToken(in, out.RoadId) {
+			t.Errorf("RoadId %q was not read from a road token in %q", out.RoadId, in)
+		}
+
+		if len(out.HectometerLetter) > 1 {
+			t.Errorf("HectometerLetter should be at most one letter, got %q", out.HectometerLetter)
+		}
+
+		if again := Parse(in); !reflect.DeepEqual(out, again) {
+			t.Errorf("Parsing %q twice gave different results: %v and %v", in, out, again)
+		}
+
+		data, err := json.Marshal(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded DescriptionDerivatives
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		// JSON replaces invalid UTF-8, so only valid input survives a round trip unchanged
+		if utf8.ValidString(in) && !reflect.DeepEqual(out, decoded) {
+			t.Errorf("JSON round trip of %q changed %v into %v", in, out, decoded)
+		}
+
+		for field := range trace.Rules {
+			for _, missing := range trace.Missing() {
+				if field == missing {
+					t.Errorf("Field %v is both traced and missing", field)
+				}
+			}
+		}
+	})
+}
+
+func FuzzParseRoadData(f *testing.F) {
+	addCorpusSeeds(f)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		trace := newTrace()
+		roadId, roadOffset, roadSide, _, _ := parseRoadData(in, &trace)
+		checkRoadData(t, roadOffset, roadSide)
+
+		if roadId != "" && !roadIdRegex.MatchString(roadId) {
+			t.Errorf("RoadId of %q should be a road prefix and digits, got %q", in, roadId)
+		}
+		if roadId != "" && !hasRoadToken(in, roadId) {
+			t.Errorf("RoadId %q was not read from a road token in %q", roadId, in)
+		}
+	})
+}
+
+func FuzzParseRoadOffset(f *testing.F) {
+	for _, seed := range []string{"12.7", "62,2", "44,570m", "21,650", "16,700", "7.7km", "-1", "1e400", "NaN", "1000", "1000,1"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		offset, ok := parseRoadOffset(in)
+		if ok && offset < 0 {
+			t.Errorf("parseRoadOffset(%q) returned negative offset %v", in, offset)
+		}
+		if ok && offset > maxRoadOffset*1000 {
+			t.Errorf("parseRoadOffset(%q) returned %v meters, beyond the %v km limit", in, offset, maxRoadOffset)
+		}
+		if !ok && offset != -1 {
+			t.Errorf("parseRoadOffset(%q) failed but returned %v instead of -1", in, offset)
+		}
+	})
+}
+
+func FuzzIsRoadId(f *testing.F) {
+	for _, seed := range []string{"A2", "N211", "N223L", "A44R", "S100", "A", "AL", "", "Poeldijk"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		if isRoadId(in) && !regexp.MustCompile(`^[ANS][0-9]+[LR]?$`).MatchString(in) {
+			t.Errorf("isRoadId(%q) accepted an id that isn't a road prefix and digits", in)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package description
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed organizations.json
+var defaultOrganizations []byte
+
+// Registry used by Parse, loaded from the embedded organizations.json
+// Use Override to add or replace entries from Go, or LoadRegistry to swap it out entirely
+var Organizations *Registry
+
+func init() {
+	registry, err := LoadRegistry(defaultOrganizations)
+	if err != nil {
+		panic("Error loading organization registry: " + err.Error())
+	}
+
+	Organizations = registry
+}
+
+type Organization struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// How to recognize an organization in a description
+// Prefixes are matched case-insensitively against the identifier in front of " - ",
+// patterns are regular expressions matched against the whole description
+type OrganizationRule struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Prefixes []string `json:"prefixes"`
+	Patterns []string `json:"patterns"`
+}
+
+type compiledRule struct {
+	Organization
+	prefixes []string
+	patterns []*regexp.Regexp
+}
+
+type Registry struct {
+	sync.RWMutex
+	rules []compiledRule
+}
+
+func compileRule(rule OrganizationRule) (compiledRule, error) {
+	if rule.Code == "" {
+		return compiledRule{}, fmt.Errorf("organization rule without code")
+	}
+
+	out := compiledRule{
+		Organization: Organization{Code: rule.Code, Name: rule.Name},
+		prefixes:     make([]string, len(rule.Prefixes)),
+		patterns:     make([]*regexp.Regexp, len(rule.Patterns)),
+	}
+
+	for i, prefix := range rule.Prefixes {
+		out.prefixes[i] = strings.ToUpper(prefix)
+	}
+
+	for i, pattern := range rule.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("organization %v: %w", rule.Code, err)
+		}
+		out.patterns[i] = re
+	}
+
+	return out, nil
+}
+
+// Creates a registry from a JSON list of OrganizationRule
+// Rules are tried in order, the first one to match wins
+func LoadRegistry(data []byte) (*Registry, error) {
+	var rules []OrganizationRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing organization registry: %w", err)
+	}
+
+	registry := &Registry{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		registry.rules = append(registry.rules, compiled)
+	}
+
+	return registry, nil
+}
+
+// Adds a rule taking precedence over all existing ones
+// Any existing rule with the same code is removed
+func (r *Registry) Override(rule OrganizationRule) error {
+	compiled, err := compileRule(rule)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	rules := make([]compiledRule, 0, len(r.rules)+1)
+	rules = append(rules, compiled)
+	for _, existing := range r.rules {
+		if existing.Code != rule.Code {
+			rules = append(rules, existing)
+		}
+	}
+	r.rules = rules
+
+	return nil
+}
+
+// Returns every organization known to the registry, in rule order
+func (r *Registry) All() []Organization {
+	r.RLock()
+	defer r.RUnlock()
+
+	out := make([]Organization, len(r.rules))
+	for i, rule := range r.rules {
+		out[i] = rule.Organization
+	}
+
+	return out
+}
+
+// Finds the organization for a description
+// identifier is the part in front of " - ", if any
+func (r *Registry) Lookup(identifier, description string) (Organization, bool) {
+	org, _, found := r.lookup(identifier, description)
+	return org, found
+}
+
+// Like Lookup, but also returns which kind of rule matched
+func (r *Registry) lookup(identifier, description string) (Organization, string, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	identifier = strings.ToUpper(identifier)
+
+	for _, rule := range r.rules {
+		if identifier != "" {
+			for _, prefix := range rule.prefixes {
+				if strings.HasPrefix(identifier, prefix) {
+					return rule.Organization, RuleOrgPrefix, true
+				}
+			}
+		}
+
+		for _, pattern := range rule.patterns {
+			if pattern.MatchString(description) {
+				return rule.Organization, RuleOrgPattern, true
+			}
+		}
+	}
+
+	return Organization{}, "", false
+}
@@ -0,0 +1,102 @@
+package description
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Carriageway string
+
+const (
+	CarriagewayUnknown   Carriageway = ""
+	CarriagewayMain      Carriageway = "hoofdrijbaan"
+	CarriagewayParallel  Carriageway = "parallelbaan"
+	CarriagewayExit      Carriageway = "afrit"
+	CarriagewayEntry     Carriageway = "toerit"
+	CarriagewayConnector Carriageway = "verbindingsweg"
+)
+
+var carriagewayLookup = map[string]Carriageway{
+	"hoofdrijbaan":   CarriagewayMain,
+	"hrb":            CarriagewayMain,
+	"parallelbaan":   CarriagewayParallel,
+	"prb":            CarriagewayParallel,
+	"afrit":          CarriagewayExit,
+	"toerit":         CarriagewayEntry,
+	"oprit":          CarriagewayEntry,
+	"verbindingsweg": CarriagewayConnector,
+	"vbw":            CarriagewayConnector,
+}
+
+var junctionWords = map[string]bool{
+	"knp":       true,
+	"knp.":      true,
+	"knooppunt": true,
+}
+
+// Words that end a junction name, like in "knp Oudenrijn richting Utrecht"
+var junctionStopWords = map[string]bool{
+	"richting": true,
+	"ri":       true,
+	"ri.":      true,
+	"voor":     true,
+	"na":       true,
+	"bij":      true,
+}
+
+// Hectometer signs can carry a letter for roads running alongside the main one, like "12,3a"
+var hectometerRegex, hectometerError = regexp.Compile(`^\d+[.,]\d+([a-hA-H])$`)
+
+func init() {
+	if hectometerError != nil {
+		panic("Error compiling regex: " + hectometerError.Error())
+	}
+}
+
+// Strips a hectometer letter from an offset, returning the offset and the lowercase letter
+func splitHectometerLetter(str string) (string, string) {
+	matches := hectometerRegex.FindStringSubmatch(str)
+	if len(matches) != 2 {
+		return str, ""
+	}
+
+	return str[:len(str)-1], strings.ToLower(matches[1])
+}
+
+// Finds carriageway type and junction name anywhere in the description
+func parsePosition(in string, out *DescriptionDerivatives, trace *Trace) {
+	fields := strings.FieldsFunc(in, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '_' || r == '(' || r == ')'
+	})
+
+	for i := 0; i < len(fields); i++ {
+		lower := strings.ToLower(fields[i])
+
+		if out.Carriageway == CarriagewayUnknown {
+			if carriageway, found := carriagewayLookup[lower]; found {
+				out.Carriageway = carriageway
+				trace.Rules["Carriageway"] = RuleCarriageway
+				continue
+			}
+		}
+
+		if out.Junction == "" && junctionWords[lower] {
+			name := make([]string, 0)
+			for i+1 < len(fields) {
+				next := strings.ToLower(fields[i+1])
+				if junctionStopWords[next] || carriagewayLookup[next] != CarriagewayUnknown || isRoadId(fields[i+1]) {
+					break
+				}
+				name = append(name, fields[i+1])
+				i++
+			}
+
+			if len(name) > 0 {
+				out.Junction = strings.Join(name, " ")
+				trace.Rules["Junction"] = RuleJunction
+			}
+			continue
+		}
+	}
+}
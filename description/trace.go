@@ -0,0 +1,87 @@
+package description
+
+type Confidence string
+
+const (
+	// Nothing could be derived from the description
+	ConfidenceNone Confidence = "none"
+	// Only a name was found, no road data
+	ConfidenceLow Confidence = "low"
+	// Some road data was found, or it was found using fallback rules
+	ConfidenceMedium Confidence = "medium"
+	// Road id, side and offset were all found using the regular rules
+	ConfidenceHigh Confidence = "high"
+)
+
+// Names of the rules that can produce a field, as reported in Trace.Rules
+const (
+	RuleOrgPrefix        = "orgPrefix"
+	RuleOrgPattern       = "orgPattern"
+	RuleRoadId           = "roadId"
+	RuleSideSuffix       = "sideSuffix"
+	RuleSideWord         = "sideWord"
+	RuleBackupRegex      = "backupRegex"
+	RuleRoadIdInName     = "roadIdInName"
+	RuleOffset           = "offset"
+	RuleRemainder        = "remainder"
+	RuleCarriageway      = "carriageway"
+	RuleJunction         = "junction"
+	RuleHectometerLetter = "hectometerLetter"
+)
+
+// Describes how Parse reached its result
+type Trace struct {
+	// Field name (as in DescriptionDerivatives) to the rule that produced it
+	// Fields that could not be extracted are absent
+	Rules map[string]string
+	// Tokens that did not end up in any field, like parenthesized ids and blacklisted words
+	Leftover   []string
+	Confidence Confidence
+}
+
+func newTrace() Trace {
+	return Trace{
+		Rules:    make(map[string]string),
+		Leftover: make([]string, 0),
+	}
+}
+
+// Fields that could not be extracted from the description
+func (t *Trace) Missing() []string {
+	fields := []string{"Organization", "RoadId", "RoadSide", "RoadOffset", "Name"}
+	out := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if _, found := t.Rules[field]; !found {
+			out = append(out, field)
+		}
+	}
+
+	return out
+}
+
+func (t *Trace) computeConfidence() Confidence {
+	roadRules := []string{t.Rules["RoadId"], t.Rules["RoadSide"], t.Rules["RoadOffset"]}
+
+	found := 0
+	usedFallback := false
+	for _, rule := range roadRules {
+		if rule != "" {
+			found++
+		}
+		if rule == RuleBackupRegex {
+			usedFallback = true
+		}
+	}
+
+	switch {
+	case found == len(roadRules) && !usedFallback:
+		return ConfidenceHigh
+	case found > 0:
+		return ConfidenceMedium
+	case len(t.Rules) > 0:
+		return ConfidenceLow
+	default:
+		return ConfidenceNone
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// What a panel is showing, only active panels show a message worth putting on the map
+type DisplayState string
+
+const (
+	DisplayActive DisplayState = "active"
+	// A single (non-black) color all over, without text
+	DisplayBlank DisplayState = "blank"
+	// A test pattern image or test text
+	DisplayTest DisplayState = "test"
+	// Nothing lit: a black image or no image, without text
+	DisplayOff DisplayState = "off"
+)
+
+// Lines used by operators to test a panel, like "TEST", "TESTBEELD" or a line of one repeated character
+var testWordRegex = regexp.MustCompile(`^(TEST|TESTBEELD|TESTTEKST|PROEF|PROEFBEELD)(\s+\d+)?$`)
+
+// Characters that light up every (or nearly every) LED of a character cell
+var testCharacters = map[rune]bool{
+	'X': true,
+	'8': true,
+	'#': true,
+	'█': true,
+}
+
+func isTestLine(line string) bool {
+	line = strings.ToUpper(strings.TrimSpace(line))
+	if testWordRegex.MatchString(line) {
+		return true
+	}
+
+	// "XXXXX", "88888" and "#####", other repeats like "-----" or "....." are real messages
+	runes := []rune(line)
+	if len(runes) < 3 || !testCharacters[runes[0]] {
+		return false
+	}
+	for _, r := range runes {
+		if r != runes[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func detectDisplayState(d Drip) DisplayState {
+	for _, line := range d.TextLines {
+		if isTestLine(line) {
+			return DisplayTest
+		}
+	}
+
+	analysis := d.ImageAnalysis
+	if d.hasImage() && analysis != nil && analysis.TestPattern {
+		return DisplayTest
+	}
+
+	if d.hasText() {
+		return DisplayActive
+	}
+
+	switch {
+	case !d.hasImage() || analysis == nil:
+		return DisplayOff
+	case analysis.Black:
+		return DisplayOff
+	case analysis.Blank:
+		return DisplayBlank
+	}
+
+	return DisplayActive
+}
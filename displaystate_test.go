@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hunternl/trafficmap/imageinfo"
+)
+
+func TestDetectDisplayState(t *testing.T) {
+	image := []byte{1}
+
+	tests := []struct {
+		name string
+		drip Drip
+		want DisplayState
+	}{
+		{"Text", Drip{TextLines: []string{"A12 FILE", "10 MIN"}}, DisplayActive},
+		{"Image", Drip{image: image, ImageAnalysis: &imageinfo.Analysis{}}, DisplayActive},
+		{"Test text", Drip{TextLines: []string{"TEST"}}, DisplayTest},
+		{"Numbered test text", Drip{TextLines: []string{"", "testbeeld 2"}}, DisplayTest},
+		{"Repeated characters", Drip{TextLines: []string{"XXXXXXXX"}}, DisplayTest},
+		{"Repeated full blocks", Drip{TextLines: []string{"█████"}}, DisplayTest},
+		{"Repeated lowercase x", Drip{TextLines: []string{"xxxx"}}, DisplayTest},
+		{"Repeated dashes", Drip{TextLines: []string{"A2 FILE", "-----"}}, DisplayActive},
+		{"Repeated exclamation marks", Drip{TextLines: []string{"!!!", "ONGEVAL"}}, DisplayActive},
+		{"Test pattern image", Drip{image: image, ImageAnalysis: &imageinfo.Analysis{TestPattern: true}}, DisplayTest},
+		{"Black image", Drip{image: image, ImageAnalysis: &imageinfo.Analysis{Blank: true, Black: true}}, DisplayOff},
+		{"Blank image", Drip{image: image, ImageAnalysis: &imageinfo.Analysis{Blank: true}}, DisplayBlank},
+		{"Blank image with text", Drip{image: image, ImageAnalysis: &imageinfo.Analysis{Blank: true}, TextLines: []string{"SPITS"}}, DisplayActive},
+		{"Nothing", Drip{TextLines: []string{"-"}}, DisplayOff},
+		{"Only spaces", Drip{TextLines: []string{"   ", "  "}}, DisplayOff},
+		{"Blank image with only spaces", Drip{image: image, ImageAnalysis: &imageinfo.Analysis{Blank: true}, TextLines: []string{"    "}}, DisplayBlank},
+		{"Word with repeated letters", Drip{TextLines: []string{"A2 FILE 3 KM"}}, DisplayActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDisplayState(tt.drip); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunternl/trafficmap/coordinate"
+	"github.com/hunternl/trafficmap/parquet"
+	"github.com/hunternl/trafficmap/traveltime"
+)
+
+// Flat tables for bulk exports, shared by the CSV and Parquet formats
+// Column names follow the JSON field names of /api/v1/
+
+var dripColumns = []parquet.Column{
+	{Name: "id", Type: parquet.String},
+	{Name: "name", Type: parquet.String},
+	{Name: "lat", Type: parquet.Double, Optional: true},
+	{Name: "lon", Type: parquet.Double, Optional: true},
+	{Name: "rdX", Type: parquet.Double, Optional: true},
+	{Name: "rdY", Type: parquet.Double, Optional: true},
+	{Name: "coordinateStatus", Type: parquet.String},
+	{Name: "working", Type: parquet.Bool},
+	{Name: "organization", Type: parquet.String},
+	{Name: "organizationCode", Type: parquet.String},
+	{Name: "roadId", Type: parquet.String},
+	{Name: "roadSide", Type: parquet.String},
+	{Name: "roadOffset", Type: parquet.Int64, Optional: true},
+	{Name: "carriageway", Type: parquet.String},
+	{Name: "junction", Type: parquet.String},
+	{Name: "hectometerLetter", Type: parquet.String},
+	// Lines joined by newlines
+	{Name: "text", Type: parquet.String},
+	{Name: "imageWidth", Type: parquet.Int64, Optional: true},
+	{Name: "imageHeight", Type: parquet.Int64, Optional: true},
+	{Name: "imageHash", Type: parquet.String, Optional: true},
+	{Name: "dateUpdated", Type: parquet.Timestamp},
+}
+
+// Values of a DRIP keyed by column name, optional columns without a value are left out
+func dripRow(d Drip, updated time.Time) map[string]any {
+	row := map[string]any{
+		"id":               d.Id,
+		"name":             d.Name,
+		"coordinateStatus": string(d.CoordinateStatus),
+		"working":          d.Working,
+		"organization":     d.Organization,
+		"organizationCode": d.OrganizationCode,
+		"roadId":           d.RoadId,
+		"roadSide":         d.RoadSide,
+		"carriageway":      d.Carriageway,
+		"junction":         d.Junction,
+		"hectometerLetter": d.HectometerLetter,
+		"text":             strings.Join(d.TextLines, "\n"),
+		"dateUpdated":      updated,
+	}
+
+	if d.CoordinateStatus != coordinate.StatusInvalid {
+		row["lat"], row["lon"] = d.Latitude, d.Longitude
+		row["rdX"], row["rdY"] = d.RdX, d.RdY
+	}
+
+	if d.RoadOffset >= 0 {
+		row["roadOffset"] = int64(d.RoadOffset)
+	}
+
+	if d.hasImage() {
+		row["imageWidth"], row["imageHeight"] = int64(d.ImageWidth), int64(d.ImageHeight)
+		row["imageHash"] = d.ImageHash
+	}
+
+	return row
+}
+
+// Orders the values of every DRIP like columns, absent values become nil
+func dripRows(drips []Drip, updated time.Time, columns []parquet.Column) [][]any {
+	rows := make([][]any, len(drips))
+	for i, drip := range drips {
+		values := dripRow(drip, updated)
+
+		row := make([]any, len(columns))
+		for c, column := range columns {
+			row[c] = values[column.Name]
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// Coordinate columns of each CRS, an export in one CRS leaves out those of the other
+var crsColumns = map[coordinate.CRS][]string{
+	coordinate.WGS84: {"lat", "lon"},
+	coordinate.RD:    {"rdX", "rdY"},
+}
+
+// Drops the coordinate columns not in crs, an empty crs keeps them all
+func dripTable(drips []Drip, updated time.Time, crs coordinate.CRS) ([]parquet.Column, [][]any) {
+	if crs == "" {
+		return dripColumns, dripRows(drips, updated, dripColumns)
+	}
+
+	drop := make(map[string]bool)
+	for other, names := range crsColumns {
+		for _, name := range names {
+			drop[name] = other != crs
+		}
+	}
+
+	columns := make([]parquet.Column, 0, len(dripColumns))
+	for _, column := range dripColumns {
+		if !drop[column.Name] {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns, dripRows(drips, updated, columns)
+}
+
+// One row per route per travel time sample
+var travelTimeColumns = []parquet.Column{
+	{Name: "id", Type: parquet.String},
+	{Name: "time", Type: parquet.Timestamp},
+	{Name: "target", Type: parquet.String},
+	{Name: "via", Type: parquet.String},
+	{Name: "minutes", Type: parquet.Int64},
+}
+
+// Rows are sorted by DRIP id, then time
+func travelTimeRows(series map[string][]traveltime.Sample) [][]any {
+	ids := make([]string, 0, len(series))
+	for id := range series {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rows := make([][]any, 0)
+	for _, id := range ids {
+		for _, sample := range series[id] {
+			for _, route := range sample.Routes {
+				rows = append(rows, []any{id, sample.Time, route.Target, route.Via, int64(route.Minutes)})
+			}
+		}
+	}
+
+	return rows
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+func writeCsv(w io.Writer, columns []parquet.Column, rows [][]any) error {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	writer.Write(header)
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, v := range row {
+			record[i] = csvValue(v)
+		}
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Picks the export format from a file name or URL path
+func writeExport(w io.Writer, name string, columns []parquet.Column, rows [][]any) error {
+	switch filepath.Ext(name) {
+	case ".csv":
+		return writeCsv(w, columns, rows)
+	case ".parquet":
+		return parquet.Write(w, columns, rows)
+	}
+	return fmt.Errorf("unknown export format for %v, expected .csv or .parquet", name)
+}
+
+func serveExport(w http.ResponseWriter, name string, columns []parquet.Column, rows [][]any) {
+	buf := &bytes.Buffer{}
+	err := writeExport(buf, name, columns, rows)
+	if err != nil {
+		fmt.Println(err.Error())
+		w.WriteHeader(500)
+		return
+	}
+
+	if filepath.Ext(name) == ".csv" {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"\"")
+	w.Write(buf.Bytes())
+}
+
+// Serves the current DRIPs as /drips.csv or /drips.parquet
+// The optional crs parameter (wgs84 or rd) keeps only the coordinates in that system
+func handleDripsExport(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crs, err := crsParam(r, "")
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		serv.Lock()
+		columns, rows := dripTable(serv.DripsSlice, serv.LastUpdate, crs)
+		serv.Unlock()
+
+		serveExport(w, strings.TrimPrefix(r.URL.Path, "/"), columns, rows)
+	})
+}
+
+// Reads the crs query parameter, returning fallback if absent
+func crsParam(r *http.Request, fallback coordinate.CRS) (coordinate.CRS, error) {
+	str := r.URL.Query().Get("crs")
+	if str == "" {
+		return fallback, nil
+	}
+
+	return coordinate.ParseCRS(str)
+}
+
+// Reads an RFC 3339 time query parameter, returning fallback if absent
+func timeParam(r *http.Request, name string, fallback time.Time) (time.Time, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return fallback, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v should be an RFC 3339 time", name)
+	}
+
+	return t, nil
+}
+
+// Serves the travel time history as /traveltimes.csv or /traveltimes.parquet
+// The optional from and to parameters limit the time range, to is exclusive
+func handleTravelTimesExport(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, err := timeParam(r, "from", time.Time{})
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		to, err := timeParam(r, "to", time.Now().Add(time.Hour))
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		rows := travelTimeRows(serv.travelTimes.Between(from, to))
+		serveExport(w, strings.TrimPrefix(r.URL.Path, "/"), travelTimeColumns, rows)
+	})
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hunternl/trafficmap/coordinate"
+	"github.com/hunternl/trafficmap/traveltime"
+)
+
+func TestDripsCsv(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/drips.csv", nil))
+
+	assert(t, recorder.Code, 200)
+	assert(t, recorder.Header().Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, len(records), 4)
+	assert(t, len(records[0]), len(dripColumns))
+
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[2][i]
+	}
+
+	assert(t, row["id"], "ID_2")
+	assert(t, row["lat"], "52.3")
+	assert(t, row["imageWidth"], "40")
+	assert(t, len(row["imageHash"]), 64)
+}
+
+func TestDripRowColumns(t *testing.T) {
+	serv := newTestServ(t)
+
+	// ID_2 has coordinates, a road offset and an image, so every column has a value
+	drip := serv.dripsMap["ID_2"]
+	drip.RoadOffset = 12700
+	row := dripRow(drip, serv.LastUpdate)
+
+	for _, column := range dripColumns {
+		if _, found := row[column.Name]; !found {
+			t.Errorf("expected a value for column %v", column.Name)
+		}
+	}
+	assert(t, len(row), len(dripColumns))
+
+	columns, rows := dripTable([]Drip{drip}, serv.LastUpdate, coordinate.RD)
+	for i, column := range columns {
+		assert(t, rows[0][i], row[column.Name])
+	}
+}
+
+func TestDripsCsvCrs(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	header := func(url string) string {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert(t, recorder.Code, 200)
+
+		records, err := csv.NewReader(recorder.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(records[0][:4], ",")
+	}
+
+	assert(t, header("/drips.csv"), "id,name,lat,lon")
+	assert(t, header("/drips.csv?crs=wgs84"), "id,name,lat,lon")
+	assert(t, header("/drips.csv?crs=EPSG:28992"), "id,name,rdX,rdY")
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/drips.csv?crs=3857", nil))
+	assert(t, recorder.Code, 400)
+}
+
+func TestDripsGeoJson(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	get := func(url string) geoJsonCollection {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert(t, recorder.Code, 200)
+		assert(t, recorder.Header().Get("Content-Type"), "application/geo+json")
+
+		var collection geoJsonCollection
+		if err := json.Unmarshal(recorder.Body.Bytes(), &collection); err != nil {
+			t.Fatal(err)
+		}
+		return collection
+	}
+
+	wgs84 := get("/drips.geojson")
+	assert(t, len(wgs84.Features), 3)
+	assert(t, wgs84.Crs == nil, true)
+	assert(t, wgs84.Features[0].Id, "ID_1")
+	assert(t, wgs84.Features[0].Geometry.Coordinates, [2]float64{4.2, 52.1})
+	assert(t, wgs84.Features[0].Properties["name"], any("Description 1"))
+	if _, found := wgs84.Features[0].Properties["lat"]; found {
+		t.Errorf("Expected coordinates only in the geometry")
+	}
+
+	rd := get("/drips.geojson?crs=rd")
+	assert(t, rd.Crs.Properties.Name, "urn:ogc:def:crs:EPSG::28992")
+	assert(t, int(rd.Features[0].Geometry.Coordinates[0]), 73656)
+	assert(t, int(rd.Features[0].Geometry.Coordinates[1]), 457526)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/drips.geojson?crs=3857", nil))
+	assert(t, recorder.Code, 400)
+}
+
+func TestDripsParquet(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/drips.parquet", nil))
+
+	assert(t, recorder.Code, 200)
+	body := recorder.Body.String()
+	if !strings.HasPrefix(body, "PAR1") || !strings.HasSuffix(body, "PAR1") {
+		t.Errorf("Expected a Parquet file")
+	}
+}
+
+func TestTravelTimesCsv(t *testing.T) {
+	serv := newTestServ(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		serv.travelTimes.Add("ID_1", start.Add(time.Duration(i)*time.Hour), []traveltime.Route{{Target: "UTRECHT", Via: "A12", Minutes: 20 + i}})
+	}
+
+	mux := createMux(serv)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/traveltimes.csv?from=2024-01-01T13:00:00Z&to=2024-01-01T14:00:00Z", nil))
+
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, len(records), 2)
+	assert(t, strings.Join(records[1], ","), "ID_1,2024-01-01T13:00:00Z,UTRECHT,A12,21")
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/traveltimes.csv?from=yesterday", nil))
+	assert(t, recorder.Code, 400)
+}
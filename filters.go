@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hunternl/trafficmap/imageinfo"
+)
+
+// Filters shared by /api/v1/drips and the DRIP lists of /graphql
+// Nil fields match every DRIP
+type DripFilter struct {
+	RoadId       *string
+	RoadSide     *string
+	Organization *string
+	Working      *bool
+	HasText      *bool
+	HasImage     *bool
+	HasRed       *bool
+	HasAmber     *bool
+	Blank        *bool
+	// One of the dominant image colors, as lowercase #rrggbb
+	Color         *string
+	DisplayStates []DisplayState
+}
+
+func (f DripFilter) Matches(d Drip) bool {
+	switch {
+	case f.RoadId != nil && !strings.EqualFold(d.RoadId, *f.RoadId):
+		return false
+	case f.RoadSide != nil && d.RoadSide != strings.ToUpper(*f.RoadSide):
+		return false
+	case f.Organization != nil && d.OrganizationCode != *f.Organization:
+		return false
+	case f.Working != nil && d.Working != *f.Working:
+		return false
+	case f.HasText != nil && d.hasText() != *f.HasText:
+		return false
+	case f.HasImage != nil && d.hasImage() != *f.HasImage:
+		return false
+	case f.DisplayStates != nil && !containsState(f.DisplayStates, d.DisplayState):
+		return false
+	}
+
+	return f.matchesImageAnalysis(d)
+}
+
+func containsState(states []DisplayState, state DisplayState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// DRIPs without an analysed image only match filters asking for false
+func (f DripFilter) matchesImageAnalysis(d Drip) bool {
+	var analysis imageinfo.Analysis
+	if d.ImageAnalysis != nil {
+		analysis = *d.ImageAnalysis
+	}
+
+	switch {
+	case f.HasRed != nil && analysis.HasRed != *f.HasRed:
+		return false
+	case f.HasAmber != nil && analysis.HasAmber != *f.HasAmber:
+		return false
+	case f.Blank != nil && analysis.Blank != *f.Blank:
+		return false
+	}
+
+	if f.Color != nil {
+		for _, dominant := range analysis.DominantColors {
+			if strings.EqualFold(dominant, *f.Color) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func filterDrips(drips []Drip, filter DripFilter) []Drip {
+	out := make([]Drip, 0, len(drips))
+	for _, drip := range drips {
+		if filter.Matches(drip) {
+			out = append(out, drip)
+		}
+	}
+	return out
+}
+
+// Skips offset DRIPs and keeps at most limit of the rest, a negative limit keeps them all
+func pageDrips(drips []Drip, offset, limit int) []Drip {
+	if offset > len(drips) {
+		offset = len(drips)
+	}
+	drips = drips[offset:]
+
+	if limit >= 0 && limit < len(drips) {
+		drips = drips[:limit]
+	}
+
+	return drips
+}
+
+func parseDisplayStates(names []string) ([]DisplayState, error) {
+	states := make([]DisplayState, 0, len(names))
+	for _, name := range names {
+		switch state := DisplayState(name); state {
+		case DisplayActive, DisplayBlank, DisplayTest, DisplayOff:
+			states = append(states, state)
+		default:
+			return nil, fmt.Errorf("displayState should be a list of active, blank, test and off")
+		}
+	}
+	return states, nil
+}
+
+func parseColor(str string) (string, error) {
+	if len(str) != 7 || str[0] != '#' {
+		return "", fmt.Errorf("color should be formatted as #rrggbb")
+	}
+	return strings.ToLower(str), nil
+}
+
+// Reads a filter from GraphQL arguments, see dripFilterArgs
+func dripFilterFromArgs(args map[string]interface{}) (DripFilter, error) {
+	filter := DripFilter{}
+
+	stringArgs := map[string]**string{
+		"roadId":       &filter.RoadId,
+		"roadSide":     &filter.RoadSide,
+		"organization": &filter.Organization,
+	}
+	for name, field := range stringArgs {
+		if value, found := args[name].(string); found {
+			*field = &value
+		}
+	}
+
+	boolArgs := map[string]**bool{
+		"working":  &filter.Working,
+		"hasText":  &filter.HasText,
+		"hasImage": &filter.HasImage,
+		"hasRed":   &filter.HasRed,
+		"hasAmber": &filter.HasAmber,
+		"blank":    &filter.Blank,
+	}
+	for name, field := range boolArgs {
+		if value, found := args[name].(bool); found {
+			*field = &value
+		}
+	}
+
+	if color, found := args["color"].(string); found {
+		color, err := parseColor(color)
+		if err != nil {
+			return filter, err
+		}
+		filter.Color = &color
+	}
+
+	if list, found := args["displayState"].([]interface{}); found {
+		names := make([]string, len(list))
+		for i, name := range list {
+			names[i], _ = name.(string)
+		}
+
+		states, err := parseDisplayStates(names)
+		if err != nil {
+			return filter, err
+		}
+		filter.DisplayStates = states
+	}
+
+	return filter, nil
+}
+
+// Reads a filter from the query of /api/v1/drips, skipping parameters that are absent
+// Parameters have the names of the GraphQL arguments
+func dripFilterFromQuery(r *http.Request) (DripFilter, error) {
+	filter := DripFilter{}
+	query := r.URL.Query()
+
+	boolParams := map[string]**bool{
+		"hasRed":   &filter.HasRed,
+		"hasAmber": &filter.HasAmber,
+		"blank":    &filter.Blank,
+	}
+	for name, field := range boolParams {
+		str := query.Get(name)
+		if str == "" {
+			continue
+		}
+
+		value, err := strconv.ParseBool(str)
+		if err != nil {
+			return filter, fmt.Errorf("%v should be true or false", name)
+		}
+		*field = &value
+	}
+
+	if str := query.Get("displayState"); str != "" {
+		states, err := parseDisplayStates(strings.Split(str, ","))
+		if err != nil {
+			return filter, err
+		}
+		filter.DisplayStates = states
+	}
+
+	if str := query.Get("color"); str != "" {
+		color, err := parseColor(str)
+		if err != nil {
+			return filter, err
+		}
+		filter.Color = &color
+	}
+
+	return filter, nil
+}
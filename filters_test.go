@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDripFilterSources(t *testing.T) {
+	fromQuery, err := dripFilterFromQuery(httptest.NewRequest("GET", "/api/v1/drips?hasRed=true&blank=false&color=%23FFB000&displayState=active,test", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromArgs, err := dripFilterFromArgs(map[string]interface{}{
+		"hasRed":       true,
+		"blank":        false,
+		"color":        "#FFB000",
+		"displayState": []interface{}{"active", "test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(fromQuery, fromArgs) {
+		t.Errorf("Expected the same filter from the query and GraphQL arguments, got %+v and %+v", fromQuery, fromArgs)
+	}
+	assert(t, *fromArgs.Color, "#ffb000")
+
+	if _, err := dripFilterFromArgs(map[string]interface{}{"displayState": []interface{}{"broken"}}); err == nil {
+		t.Errorf("Expected an error for an unknown display state")
+	}
+	if _, err := dripFilterFromArgs(map[string]interface{}{"color": "red"}); err == nil {
+		t.Errorf("Expected an error for a color that isn't #rrggbb")
+	}
+}
+
+func TestDripFilterMatches(t *testing.T) {
+	serv := newTestServ(t)
+
+	yes, no := true, false
+	roadSide := "l"
+
+	assert(t, len(filterDrips(serv.DripsSlice, DripFilter{})), 3)
+	assert(t, len(filterDrips(serv.DripsSlice, DripFilter{Working: &no})), 1)
+	assert(t, len(filterDrips(serv.DripsSlice, DripFilter{Working: &yes, RoadSide: &roadSide})), 0)
+	assert(t, len(filterDrips(serv.DripsSlice, DripFilter{DisplayStates: []DisplayState{}})), 0)
+	assert(t, len(pageDrips(serv.DripsSlice, 1, -1)), 2)
+	assert(t, len(pageDrips(serv.DripsSlice, 5, 1)), 0)
+}
+
+func TestRenderedImagesDontMatchImageFilters(t *testing.T) {
+	serv := newTestServ(t)
+	yes := true
+
+	// ID_1 only has text, drawn in amber, but the DRIP itself sends no image
+	textOnly := serv.dripsMap["ID_1"]
+	assert(t, textOnly.ImageRendered, true)
+	assert(t, DripFilter{HasAmber: &yes}.Matches(textOnly), false)
+	assert(t, DripFilter{HasImage: &yes}.Matches(textOnly), false)
+
+	for _, drip := range filterDrips(serv.DripsSlice, DripFilter{HasImage: &yes}) {
+		assert(t, drip.Id, "ID_2")
+	}
+
+	images := distinctImages(serv.DripsSlice, serv.LastUpdate)
+	assert(t, len(images.Images), 1)
+	assert(t, images.Images[0].DripIds[0], "ID_2")
+
+	// It still has an image to show on the map
+	assert(t, toApiDrip(textOnly).Image.Rendered, true)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hunternl/trafficmap/coordinate"
+)
+
+type geoJsonPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoJsonFeature struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+	// Null for DRIPs without valid coordinates
+	Geometry   *geoJsonPoint  `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Named CRS member from the 2008 GeoJSON spec, RFC 7946 dropped it but GIS tools still read it
+type geoJsonCrs struct {
+	Type       string `json:"type"`
+	Properties struct {
+		Name string `json:"name"`
+	} `json:"properties"`
+}
+
+type geoJsonCollection struct {
+	Type     string           `json:"type"`
+	Crs      *geoJsonCrs      `json:"crs,omitempty"`
+	Features []geoJsonFeature `json:"features"`
+}
+
+// Writes DRIPs as GeoJSON points in crs, with the export columns as properties
+// Coordinates are [longitude, latitude] for WGS84 and [x, y] for RD
+func writeGeoJson(w io.Writer, drips []Drip, updated time.Time, crs coordinate.CRS) error {
+	collection := geoJsonCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJsonFeature, len(drips)),
+	}
+
+	// WGS84 is the GeoJSON default, other systems have to be named
+	if crs != coordinate.WGS84 {
+		collection.Crs = &geoJsonCrs{Type: "name"}
+		collection.Crs.Properties.Name = fmt.Sprintf("urn:ogc:def:crs:EPSG::%v", crs[len("EPSG:"):])
+	}
+
+	// The position is in the geometry, so every coordinate column is left out
+	skip := make(map[string]bool)
+	for _, names := range crsColumns {
+		for _, name := range names {
+			skip[name] = true
+		}
+	}
+
+	for i, drip := range drips {
+		row := dripRow(drip, updated)
+		properties := make(map[string]any, len(dripColumns))
+		for _, column := range dripColumns {
+			if !skip[column.Name] {
+				properties[column.Name] = row[column.Name]
+			}
+		}
+
+		feature := geoJsonFeature{Type: "Feature", Id: drip.Id, Properties: properties}
+		if drip.CoordinateStatus != coordinate.StatusInvalid {
+			x, y := crs.FromWGS84(drip.Latitude, drip.Longitude)
+			feature.Geometry = &geoJsonPoint{Type: "Point", Coordinates: [2]float64{x, y}}
+		}
+
+		collection.Features[i] = feature
+	}
+
+	return json.NewEncoder(w).Encode(collection)
+}
+
+// Serves the current DRIPs as /drips.geojson, in WGS84 unless crs=rd is given
+func handleGeoJson(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crs, err := crsParam(r, coordinate.WGS84)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		buf := &bytes.Buffer{}
+
+		serv.Lock()
+		err = writeGeoJson(buf, serv.DripsSlice, serv.LastUpdate, crs)
+		serv.Unlock()
+
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write(buf.Bytes())
+	})
+}
@@ -1,3 +1,17 @@
 module github.com/hunternl/trafficmap
 
-go 1.20
+go 1.21
+
+require (
+	github.com/graphql-go/graphql v0.8.1
+	golang.org/x/image v0.18.0
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.1
+)
+
+require (
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
+)
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+type snapshotKey struct{}
+
+// Resolvers read DRIPs from a snapshot taken at the start of a request, so the whole
+// query sees the same update cycle. Subscriptions have no snapshot and read the live state.
+func dripsFromContext(ctx context.Context, serv *DripServ) []Drip {
+	if drips, found := ctx.Value(snapshotKey{}).([]Drip); found {
+		return drips
+	}
+
+	serv.Lock()
+	defer serv.Unlock()
+
+	return serv.DripsSlice
+}
+
+type gqlRoad struct {
+	Id    string
+	drips []Drip
+}
+
+type gqlOrganization struct {
+	Code  string
+	Name  string
+	drips []Drip
+}
+
+func roadFor(drips []Drip, roadId string) *gqlRoad {
+	roadDrips, found := groupByRoad(drips)[roadId]
+	if !found {
+		return nil
+	}
+
+	sortByOffset(roadDrips)
+	return &gqlRoad{Id: roadId, drips: roadDrips}
+}
+
+func organizationFor(drips []Drip, code string) *gqlOrganization {
+	var out *gqlOrganization
+
+	for _, drip := range drips {
+		if drip.OrganizationCode != code {
+			continue
+		}
+		if out == nil {
+			out = &gqlOrganization{Code: code, Name: drip.Organization}
+		}
+		out.drips = append(out.drips, drip)
+	}
+
+	return out
+}
+
+func toApiDrips(drips []Drip) []ApiDrip {
+	out := make([]ApiDrip, len(drips))
+	for i, drip := range drips {
+		out[i] = toApiDrip(drip)
+	}
+	return out
+}
+
+// Filters and pages DRIPs by the dripFilterArgs of a field
+func resolveDrips(drips []Drip, args map[string]interface{}) ([]ApiDrip, error) {
+	filter, err := dripFilterFromArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, _ := args["offset"].(int)
+	limit, found := args["limit"].(int)
+	if !found {
+		limit = -1
+	}
+
+	return toApiDrips(pageDrips(filterDrips(drips, filter), offset, limit)), nil
+}
+
+var dripFilterArgs = graphql.FieldConfigArgument{
+	"roadId":       &graphql.ArgumentConfig{Type: graphql.String},
+	"roadSide":     &graphql.ArgumentConfig{Type: graphql.String, Description: "L or R"},
+	"organization": &graphql.ArgumentConfig{Type: graphql.String, Description: "Organization code"},
+	"working":      &graphql.ArgumentConfig{Type: graphql.Boolean},
+	"hasText":      &graphql.ArgumentConfig{Type: graphql.Boolean},
+	"hasImage":     &graphql.ArgumentConfig{Type: graphql.Boolean},
+	"hasRed":       &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Image has a red warning area"},
+	"hasAmber":     &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Image has an amber warning area"},
+	"blank":        &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Image is a single color"},
+	"color":        &graphql.ArgumentConfig{Type: graphql.String, Description: "One of the dominant image colors, as #rrggbb"},
+	"displayState": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Display states to include: active, blank, test or off"},
+	"offset":       &graphql.ArgumentConfig{Type: graphql.Int},
+	"limit":        &graphql.ArgumentConfig{Type: graphql.Int},
+}
+
+func newGraphQLSchema(serv *DripServ) (graphql.Schema, error) {
+	rectType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Rect",
+		Fields: graphql.Fields{
+			"x":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"y":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"width":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"height": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	analysisType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ImageAnalysis",
+		Fields: graphql.Fields{
+			"dominantColors": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"hasRed":         &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasAmber":       &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"blank":          &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"black":          &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"testPattern":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"textAreas":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(rectType)))},
+		},
+	})
+
+	imageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Image",
+		Fields: graphql.Fields{
+			"url":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"hash": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"perceptualHash": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*ApiImage).PerceptualHash.String(), nil
+				},
+			},
+			"width":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"height":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"analysis": &graphql.Field{Type: graphql.NewNonNull(analysisType)},
+			"rendered": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "Drawn from the text lines, for panels that only send text"},
+		},
+	})
+
+	routeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Route",
+		Fields: graphql.Fields{
+			"target":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"via":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"minutes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	dripType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Drip",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"lat":              &graphql.Field{Type: graphql.Float},
+			"lon":              &graphql.Field{Type: graphql.Float},
+			"rdX":              &graphql.Field{Type: graphql.Float},
+			"rdY":              &graphql.Field{Type: graphql.Float},
+			"working":          &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"organizationCode": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"roadId":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"roadSide":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"roadOffset":       &graphql.Field{Type: graphql.Int},
+			"carriageway":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"junction":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"hectometerLetter": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"text":             &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"routes":           &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(routeType)))},
+			"image":            &graphql.Field{Type: imageType},
+			"coordinateStatus": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(ApiDrip).CoordinateStatus), nil
+				},
+			},
+			"displayState": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(ApiDrip).DisplayState), nil
+				},
+			},
+		},
+	})
+
+	roadType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Road",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"count": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return len(p.Source.(*gqlRoad).drips), nil
+				},
+			},
+			"nonWorking": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					count := 0
+					for _, drip := range p.Source.(*gqlRoad).drips {
+						if !drip.Working {
+							count++
+						}
+					}
+					return count, nil
+				},
+			},
+			"drips": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dripType))),
+				Description: "DRIPs along this road, ordered by offset",
+				Args:        dripFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveDrips(p.Source.(*gqlRoad).drips, p.Args)
+				},
+			},
+		},
+	})
+
+	organizationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Organization",
+		Fields: graphql.Fields{
+			"code": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"count": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return len(p.Source.(*gqlOrganization).drips), nil
+				},
+			},
+			"drips": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dripType))),
+				Args: dripFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveDrips(p.Source.(*gqlOrganization).drips, p.Args)
+				},
+			},
+		},
+	})
+
+	// Added afterwards as these types refer to each other
+	dripType.AddFieldConfig("road", &graphql.Field{
+		Type: roadType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if road := roadFor(dripsFromContext(p.Context, serv), p.Source.(ApiDrip).RoadId); road != nil {
+				return road, nil
+			}
+			return nil, nil
+		},
+	})
+
+	dripType.AddFieldConfig("organization", &graphql.Field{
+		Type: organizationType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if org := organizationFor(dripsFromContext(p.Context, serv), p.Source.(ApiDrip).OrganizationCode); org != nil {
+				return org, nil
+			}
+			return nil, nil
+		},
+	})
+
+	updateType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "DripUpdate",
+		Description: "DRIPs that changed during an update cycle",
+		Fields: graphql.Fields{
+			"time":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"changed": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dripType)))},
+			"removed": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Description: "Ids of DRIPs no longer shown that matched the filter when last seen",
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"drips": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dripType))),
+				Args: dripFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveDrips(dripsFromContext(p.Context, serv), p.Args)
+				},
+			},
+			"drip": &graphql.Field{
+				Type: dripType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					for _, drip := range dripsFromContext(p.Context, serv) {
+						if drip.Id == p.Args["id"] {
+							return toApiDrip(drip), nil
+						}
+					}
+					return nil, nil
+				},
+			},
+			"roads": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(roadType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					drips := dripsFromContext(p.Context, serv)
+					out := make([]*gqlRoad, 0)
+					for _, summary := range summarizeRoads(drips) {
+						out = append(out, roadFor(drips, summary.RoadId))
+					}
+					return out, nil
+				},
+			},
+			"road": &graphql.Field{
+				Type: roadType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if road := roadFor(dripsFromContext(p.Context, serv), strings.ToUpper(p.Args["id"].(string))); road != nil {
+						return road, nil
+					}
+					return nil, nil
+				},
+			},
+			"organizations": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(organizationType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					drips := dripsFromContext(p.Context, serv)
+					out := make([]*gqlOrganization, 0)
+					for _, count := range countOrganizations(drips) {
+						if count.Count > 0 && count.Code != "" {
+							out = append(out, organizationFor(drips, count.Code))
+						}
+					}
+					return out, nil
+				},
+			},
+			"organization": &graphql.Field{
+				Type: organizationType,
+				Args: graphql.FieldConfigArgument{
+					"code": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if org := organizationFor(dripsFromContext(p.Context, serv), p.Args["code"].(string)); org != nil {
+						return org, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"dripsUpdated": &graphql.Field{
+				Type:        graphql.NewNonNull(updateType),
+				Description: "Emits the DRIPs that changed after every update cycle, filtered like Query.drips",
+				Args:        dripFilterArgs,
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					filter, err := dripFilterFromArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+
+					updates := serv.updates.Subscribe()
+					out := make(chan interface{})
+
+					// Remembers every DRIP as last seen, so removals can be filtered as well
+					known := make(map[string]Drip)
+					serv.Lock()
+					for _, drip := range serv.DripsSlice {
+						known[drip.Id] = drip
+					}
+					serv.Unlock()
+
+					go func() {
+						defer close(out)
+						defer serv.updates.Unsubscribe(updates)
+
+						for {
+							select {
+							case <-p.Context.Done():
+								return
+							case changes, ok := <-updates:
+								// Closed when this subscriber fell behind, ending it makes the client subscribe again
+								if !ok {
+									return
+								}
+
+								// The arguments were checked before subscribing
+								changed, _ := resolveDrips(changes.Changed, p.Args)
+								removed := make([]string, 0)
+								for _, id := range changes.Removed {
+									if previous, found := known[id]; found && filter.Matches(previous) {
+										removed = append(removed, id)
+									}
+									delete(known, id)
+								}
+								for _, drip := range changes.Changed {
+									known[drip.Id] = drip
+								}
+
+								payload := map[string]interface{}{
+									"time":    changes.Time,
+									"changed": changed,
+									"removed": removed,
+								}
+
+								select {
+								case out <- payload:
+								case <-p.Context.Done():
+									return
+								}
+							}
+						}
+					}()
+
+					return out, nil
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Subscription: subscription,
+	})
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func readGraphQLRequest(r *http.Request) (graphQLRequest, error) {
+	req := graphQLRequest{}
+
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid request body: %w", err)
+		}
+		return req, nil
+	}
+
+	query := r.URL.Query()
+	req.Query = query.Get("query")
+	req.OperationName = query.Get("operationName")
+
+	if variables := query.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return req, fmt.Errorf("invalid variables: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// Serves queries over GET and POST, and subscriptions as server-sent events
+func handleGraphQL(serv *DripServ) http.HandlerFunc {
+	schema, err := newGraphQLSchema(serv)
+	if err != nil {
+		panic("Error creating GraphQL schema: " + err.Error())
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := readGraphQLRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		params := graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			OperationName:  req.OperationName,
+			VariableValues: req.Variables,
+		}
+
+		if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			serveSubscription(w, r, params)
+			return
+		}
+
+		serv.Lock()
+		snapshot := serv.DripsSlice
+		serv.Unlock()
+
+		params.Context = context.WithValue(r.Context(), snapshotKey{}, snapshot)
+		writeJson(w, graphql.Do(params))
+	})
+}
+
+func serveSubscription(w http.ResponseWriter, r *http.Request, params graphql.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", 500)
+		return
+	}
+
+	params.Context = r.Context()
+	results := graphql.Subscribe(params)
+
+	// Keep draining so the executor doesn't block forever once the client is gone
+	defer func() {
+		go func() {
+			for range results {
+			}
+		}()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(200)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case result, more := <-results:
+			if !more {
+				return
+			}
+
+			str, err := json.Marshal(result)
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+
+			fmt.Fprintf(w, "event: next\ndata: %s\n\n", str)
+			flusher.Flush()
+		}
+	}
+}
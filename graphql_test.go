@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestGraphQLQuery(t *testing.T) {
+	mux := createMux(newTestServ(t))
+
+	body := `{"query": "{ drips(hasImage: true) { id lat image { width height rendered } } drip(id: \"ID_1\") { name text road { id } image { rendered } } }"}`
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+
+	var result struct {
+		Data struct {
+			Drips []struct {
+				Id    string
+				Lat   float64
+				Image struct {
+					Width, Height int
+					Rendered      bool
+				}
+			}
+			Drip struct {
+				Name  string
+				Text  []string
+				Road  *struct{ Id string }
+				Image struct{ Rendered bool }
+			}
+		}
+		Errors []any
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	// ID_1 only has text, its rendered image doesn't count for hasImage
+	assert(t, len(result.Data.Drips), 1)
+	assert(t, result.Data.Drips[0].Id, "ID_2")
+	assert(t, result.Data.Drips[0].Lat, 52.3)
+	assert(t, result.Data.Drips[0].Image.Width, 40)
+	assert(t, result.Data.Drips[0].Image.Rendered, false)
+	assert(t, result.Data.Drip.Name, "Description 1")
+	assert(t, result.Data.Drip.Text[0], "Textline 1")
+	assert(t, result.Data.Drip.Image.Rendered, true)
+
+	if result.Data.Drip.Road != nil {
+		t.Errorf("Expected no road for a DRIP without road id, got %v", result.Data.Drip.Road)
+	}
+}
+
+// Subscribes with the given query and waits for the subscriber to register
+func subscribeGraphQL(t *testing.T, serv *DripServ, query string) chan *graphql.Result {
+	t.Helper()
+
+	schema, err := newGraphQLSchema(serv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+		Context:       ctx,
+	})
+
+	for {
+		serv.updates.Lock()
+		subscribed := len(serv.updates.subscribers) > 0
+		serv.updates.Unlock()
+		if subscribed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return results
+}
+
+func TestGraphQLSubscription(t *testing.T) {
+	serv := newTestServ(t)
+	results := subscribeGraphQL(t, serv, `subscription { dripsUpdated(working: false) { changed { id } removed } }`)
+
+	// ID_1 was working, so its removal doesn't match, ID_4 was never seen
+	serv.updates.Publish(DripChanges{
+		Time:    time.Now(),
+		Changed: serv.DripsSlice,
+		Removed: []string{"ID_1", "ID_3", "ID_4"},
+	})
+
+	result := <-results
+	if result.HasErrors() {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	str, _ := json.Marshal(result.Data)
+	assert(t, string(str), `{"dripsUpdated":{"changed":[{"id":"ID_3"}],"removed":["ID_3"]}}`)
+}
+
+func TestGraphQLSubscriptionRemovedByRoad(t *testing.T) {
+	serv := newTestServ(t)
+	serv.DripsSlice[0].RoadId = "A2"
+	serv.DripsSlice[1].RoadId = "A12"
+	results := subscribeGraphQL(t, serv, `subscription { dripsUpdated(roadId: "A2") { changed { id } removed } }`)
+
+	// ID_2 moves onto the A2 and is removed in the next cycle
+	moved := serv.DripsSlice[1]
+	moved.RoadId = "A2"
+	serv.updates.Publish(DripChanges{
+		Time:    time.Now(),
+		Removed: []string{serv.DripsSlice[0].Id, serv.DripsSlice[2].Id},
+	})
+	serv.updates.Publish(DripChanges{
+		Time:    time.Now(),
+		Changed: []Drip{moved},
+	})
+	serv.updates.Publish(DripChanges{
+		Time:    time.Now(),
+		Removed: []string{moved.Id},
+	})
+
+	expected := []string{
+		`{"dripsUpdated":{"changed":[],"removed":["ID_1"]}}`,
+		`{"dripsUpdated":{"changed":[{"id":"ID_2"}],"removed":[]}}`,
+		`{"dripsUpdated":{"changed":[],"removed":["ID_2"]}}`,
+	}
+	for _, want := range expected {
+		result := <-results
+		if result.HasErrors() {
+			t.Fatalf("Unexpected errors: %v", result.Errors)
+		}
+
+		str, _ := json.Marshal(result.Data)
+		assert(t, string(str), want)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/hunternl/trafficmap/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative rpc/drips.proto
+
+// Page size used by ListDrips when the client doesn't ask for one, as documented in drips.proto
+const defaultPageSize = 100
+
+// Implements rpc.DripsServer on top of the same data /api/v1/ serves
+type grpcServer struct {
+	rpc.UnimplementedDripsServer
+	serv *DripServ
+}
+
+func toRpcDrip(d Drip) *rpc.Drip {
+	api := toApiDrip(d)
+
+	out := &rpc.Drip{
+		Id:               d.Id,
+		Name:             d.Name,
+		Lat:              api.Lat,
+		Lon:              api.Lon,
+		RdX:              api.RdX,
+		RdY:              api.RdY,
+		CoordinateStatus: string(d.CoordinateStatus),
+		Working:          d.Working,
+		Organization:     d.Organization,
+		OrganizationCode: d.OrganizationCode,
+		RoadId:           d.RoadId,
+		RoadSide:         d.RoadSide,
+		RoadOffset:       int32(d.RoadOffset),
+		Carriageway:      d.Carriageway,
+		Junction:         d.Junction,
+		HectometerLetter: d.HectometerLetter,
+		Text:             d.TextLines,
+	}
+
+	for _, route := range d.Routes {
+		out.Routes = append(out.Routes, &rpc.Route{
+			Target:  route.Target,
+			Via:     route.Via,
+			Minutes: int32(route.Minutes),
+		})
+	}
+
+	if api.Image != nil {
+		out.Image = &rpc.Image{
+			Url:            api.Image.Url,
+			Width:          int32(api.Image.Width),
+			Height:         int32(api.Image.Height),
+			Hash:           api.Image.Hash,
+			PerceptualHash: api.Image.PerceptualHash.String(),
+			Rendered:       api.Image.Rendered,
+		}
+	}
+
+	return out
+}
+
+func (g *grpcServer) ListDrips(ctx context.Context, req *rpc.ListDripsRequest) (*rpc.ListDripsResponse, error) {
+	offset := 0
+	if req.PageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(req.PageToken)
+		if err != nil || offset < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page size should not be negative")
+	}
+	pageSize := int(req.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	g.serv.Lock()
+	defer g.serv.Unlock()
+
+	drips := make([]Drip, 0)
+	for _, drip := range g.serv.DripsSlice {
+		if req.RoadId != "" && drip.RoadId != req.RoadId {
+			continue
+		}
+		if req.OrganizationCode != "" && drip.OrganizationCode != req.OrganizationCode {
+			continue
+		}
+		drips = append(drips, drip)
+	}
+
+	out := &rpc.ListDripsResponse{Total: int32(len(drips))}
+
+	if offset > len(drips) {
+		offset = len(drips)
+	}
+	end := offset + pageSize
+	if end < len(drips) {
+		out.NextPageToken = strconv.Itoa(end)
+	} else {
+		end = len(drips)
+	}
+
+	for _, drip := range drips[offset:end] {
+		out.Drips = append(out.Drips, toRpcDrip(drip))
+	}
+
+	return out, nil
+}
+
+func (g *grpcServer) GetDrip(ctx context.Context, req *rpc.GetDripRequest) (*rpc.Drip, error) {
+	g.serv.Lock()
+	drip, found := g.serv.dripsMap[req.Id]
+	g.serv.Unlock()
+
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no DRIP with id %v", req.Id)
+	}
+
+	return toRpcDrip(drip), nil
+}
+
+// Only includes changes to DRIPs on the given road, or all when roadId is empty
+func toDripEvent(changes DripChanges, roadId string, previous map[string]Drip) *rpc.DripEvent {
+	event := &rpc.DripEvent{UpdateTimeUnixMs: changes.Time.UnixMilli()}
+
+	for _, drip := range changes.Changed {
+		if roadId == "" || drip.RoadId == roadId {
+			event.Changed = append(event.Changed, toRpcDrip(drip))
+		}
+	}
+
+	for _, id := range changes.Removed {
+		if roadId == "" || previous[id].RoadId == roadId {
+			event.Removed = append(event.Removed, id)
+		}
+	}
+
+	return event
+}
+
+func (g *grpcServer) WatchDrips(req *rpc.WatchDripsRequest, stream rpc.Drips_WatchDripsServer) error {
+	updates := g.serv.updates.Subscribe()
+	defer g.serv.updates.Unsubscribe(updates)
+
+	// Remembers the road of every DRIP sent, so removals can be filtered as well
+	known := make(map[string]Drip)
+
+	g.serv.Lock()
+	current := DripChanges{Time: g.serv.LastUpdate, Changed: g.serv.DripsSlice}
+	g.serv.Unlock()
+
+	for _, drip := range current.Changed {
+		known[drip.Id] = drip
+	}
+
+	if req.Initial {
+		if err := stream.Send(toDripEvent(current, req.RoadId, known)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case changes, ok := <-updates:
+			// Closed when this stream fell behind, so changes were missed
+			if !ok {
+				return status.Error(codes.Unavailable, "fell behind on updates, reconnect with initial set")
+			}
+
+			event := toDripEvent(changes, req.RoadId, known)
+			for _, drip := range changes.Changed {
+				known[drip.Id] = drip
+			}
+			for _, id := range changes.Removed {
+				delete(known, id)
+			}
+
+			if len(event.Changed) == 0 && len(event.Removed) == 0 {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newGrpcServer(serv *DripServ) *grpc.Server {
+	server := grpc.NewServer()
+	rpc.RegisterDripsServer(server, &grpcServer{serv: serv})
+	return server
+}
+
+func ServeGrpc(addr string, port int, serv *DripServ) {
+	fullAddr := fmt.Sprintf("%v:%v", addr, port)
+
+	listener, err := net.Listen("tcp", fullAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Serving gRPC at %v\n", fullAddr)
+	err = newGrpcServer(serv).Serve(listener)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hunternl/trafficmap/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestGrpcClient(t *testing.T, serv *DripServ) rpc.DripsClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := newGrpcServer(serv)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return rpc.NewDripsClient(conn)
+}
+
+func TestGrpcListAndGet(t *testing.T) {
+	client := newTestGrpcClient(t, newTestServ(t))
+	ctx := context.Background()
+
+	page, err := client.ListDrips(ctx, &rpc.ListDripsRequest{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, page.Total, 3)
+	assert(t, len(page.Drips), 2)
+	assert(t, page.NextPageToken, "2")
+
+	page, err = client.ListDrips(ctx, &rpc.ListDripsRequest{PageSize: 2, PageToken: page.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, len(page.Drips), 1)
+	assert(t, page.NextPageToken, "")
+
+	// A page size of 0 means defaultPageSize, which fits every test DRIP
+	page, err = client.ListDrips(ctx, &rpc.ListDripsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, len(page.Drips), 3)
+	assert(t, page.NextPageToken, "")
+
+	drip, err := client.GetDrip(ctx, &rpc.GetDripRequest{Id: "ID_2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, drip.Id, "ID_2")
+	assert(t, *drip.Lat, 52.3)
+
+	_, err = client.GetDrip(ctx, &rpc.GetDripRequest{Id: "UNKNOWN_ID"})
+	assert(t, status.Code(err), codes.NotFound)
+
+	_, err = client.ListDrips(ctx, &rpc.ListDripsRequest{PageToken: "invalid"})
+	assert(t, status.Code(err), codes.InvalidArgument)
+}
+
+func TestGrpcWatch(t *testing.T) {
+	serv := newTestServ(t)
+	client := newTestGrpcClient(t, serv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchDrips(ctx, &rpc.WatchDripsRequest{Initial: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, len(event.Changed), 3)
+
+	changed := serv.DripsSlice[0]
+	changed.Working = !changed.Working
+	serv.updates.Publish(DripChanges{
+		Time:    time.UnixMilli(1000),
+		Changed: []Drip{changed},
+		Removed: []string{serv.DripsSlice[1].Id},
+	})
+
+	event, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, event.UpdateTimeUnixMs, 1000)
+	assert(t, len(event.Changed), 1)
+	assert(t, event.Changed[0].Working, changed.Working)
+	assert(t, len(event.Removed), 1)
+	assert(t, event.Removed[0], serv.DripsSlice[1].Id)
+}
@@ -0,0 +1,307 @@
+package imageinfo
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type Analysis struct {
+	// Most common colors as "#rrggbb", most common first, each covering at least minColorShare of the image
+	DominantColors []string `json:"dominantColors"`
+	// Whether a noticeable part of the image is warning red or amber
+	HasRed   bool `json:"hasRed"`
+	HasAmber bool `json:"hasAmber"`
+	// A single color all over, which shows up as an empty marker
+	Blank bool `json:"blank"`
+	// Blank and (nearly) black, like a panel that is switched off
+	Black bool `json:"black"`
+	// Evenly wide color bars with several saturated colors, or a two color checkerboard
+	TestPattern bool `json:"testPattern"`
+	// Areas that differ from the background, roughly one per line of text or pictogram
+	TextAreas []Rect `json:"textAreas"`
+}
+
+const maxDominantColors = 3
+
+// Share of pixels a color needs to count as dominant
+const minColorShare = 0.02
+
+// Share of pixels in a single color for an image to be blank
+const blankShare = 0.995
+
+// Share of pixels in a bar or checkerboard cell that may differ from its color, like compression artifacts
+const testPatternNoise = 0.05
+
+// Color bars need this many bars of distinct colors, with at least minSaturatedBars saturated ones,
+// photos and pictograms rarely have every line across them in the same few bright colors
+const minTestBars = 3
+const minSaturatedBars = 2
+
+// Share of pixels that needs to be red or amber to count as a warning area
+const minWarningShare = 0.01
+
+// Colors are counted in buckets of 32 levels per channel, to even out anti-aliasing
+const colorBucketBits = 3
+
+type bucket struct {
+	r, g, b uint8
+}
+
+func bucketOf(c color.Color) bucket {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+
+	// Transparent pixels show the (black) map panel background
+	if rgba.A < 128 {
+		return bucket{}
+	}
+
+	return bucket{rgba.R >> (8 - colorBucketBits), rgba.G >> (8 - colorBucketBits), rgba.B >> (8 - colorBucketBits)}
+}
+
+// Center color of a bucket
+func (b bucket) rgb() (r, g, bl uint8) {
+	const shift = 8 - colorBucketBits
+	const half = 1 << (shift - 1)
+	return b.r<<shift | half, b.g<<shift | half, b.b<<shift | half
+}
+
+func (b bucket) hex() string {
+	r, g, bl := b.rgb()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, bl)
+}
+
+func (b bucket) luminance() float64 {
+	r, g, bl := b.rgb()
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+}
+
+func (b bucket) isSaturated() bool {
+	r, g, bl := b.rgb()
+	return max(r, g, bl) >= 160 && int(max(r, g, bl))-int(min(r, g, bl)) >= 128
+}
+
+func (b bucket) isRed() bool {
+	r, g, bl := b.rgb()
+	return r >= 160 && g <= 90 && bl <= 90
+}
+
+func (b bucket) isAmber() bool {
+	r, g, bl := b.rgb()
+	return r >= 180 && g >= 100 && g <= 210 && bl <= 90
+}
+
+func Analyze(img image.Image) Analysis {
+	out := Analysis{DominantColors: make([]string, 0), TextAreas: make([]Rect, 0)}
+
+	bounds := img.Bounds()
+	total := bounds.Dx() * bounds.Dy()
+	if total == 0 {
+		return out
+	}
+
+	buckets := make([]bucket, total)
+	counts := make(map[bucket]int)
+	red, amber := 0, 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			b := bucketOf(img.At(x, y))
+			buckets[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)] = b
+			counts[b]++
+
+			if b.isRed() {
+				red++
+			} else if b.isAmber() {
+				amber++
+			}
+		}
+	}
+
+	sorted := make([]bucket, 0, len(counts))
+	for b := range counts {
+		sorted = append(sorted, b)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if counts[sorted[i]] != counts[sorted[j]] {
+			return counts[sorted[i]] > counts[sorted[j]]
+		}
+		return sorted[i].hex() < sorted[j].hex()
+	})
+
+	for _, b := range sorted {
+		if len(out.DominantColors) == maxDominantColors || float64(counts[b]) < minColorShare*float64(total) {
+			break
+		}
+		out.DominantColors = append(out.DominantColors, b.hex())
+	}
+
+	background := sorted[0]
+	out.Blank = float64(counts[background]) >= blankShare*float64(total)
+	out.Black = out.Blank && background.luminance() < 40
+	out.TestPattern = isColorBars(buckets, bounds.Dx(), bounds.Dy(), false) ||
+		isColorBars(buckets, bounds.Dx(), bounds.Dy(), true) ||
+		isCheckerboard(buckets, bounds.Dx(), bounds.Dy())
+	out.HasRed = float64(red) >= minWarningShare*float64(total)
+	out.HasAmber = float64(amber) >= minWarningShare*float64(total)
+
+	if !out.Blank {
+		out.TextAreas = textAreas(buckets, bounds.Dx(), bounds.Dy(), background)
+	}
+
+	return out
+}
+
+// Checks for vertical color bars, or horizontal ones when transposed
+// Every line across a bar has to be its color, and no bar may be more than twice as wide as another
+func isColorBars(buckets []bucket, width, height int, transposed bool) bool {
+	at := func(along, across int) bucket {
+		if transposed {
+			return buckets[along*width+across]
+		}
+		return buckets[across*width+along]
+	}
+
+	length, breadth := width, height
+	if transposed {
+		length, breadth = height, width
+	}
+
+	type bar struct {
+		color bucket
+		width int
+	}
+	bars := make([]bar, 0)
+
+	for i := 0; i < length; i++ {
+		lineColor := at(i, breadth/2)
+
+		matching := 0
+		for j := 0; j < breadth; j++ {
+			if at(i, j) == lineColor {
+				matching++
+			}
+		}
+		if float64(matching) < (1-testPatternNoise)*float64(breadth) {
+			return false
+		}
+
+		if len(bars) > 0 && bars[len(bars)-1].color == lineColor {
+			bars[len(bars)-1].width++
+		} else {
+			bars = append(bars, bar{lineColor, 1})
+		}
+	}
+
+	colors := make(map[bucket]bool)
+	saturated, narrowest, widest := 0, length, 0
+	for _, b := range bars {
+		// Blended lines between two bars don't count as a bar
+		if b.width < 2 {
+			continue
+		}
+
+		if !colors[b.color] && b.color.isSaturated() {
+			saturated++
+		}
+		colors[b.color] = true
+		narrowest = min(narrowest, b.width)
+		widest = max(widest, b.width)
+	}
+
+	return len(colors) >= minTestBars && saturated >= minSaturatedBars && widest <= 2*narrowest
+}
+
+// Checks for two colors alternating in equally sized cells, with at least two cells each way
+func isCheckerboard(buckets []bucket, width, height int) bool {
+	first := buckets[0]
+
+	cellWidth, cellHeight := 1, 1
+	for cellWidth < width && buckets[cellWidth] == first {
+		cellWidth++
+	}
+	for cellHeight < height && buckets[cellHeight*width] == first {
+		cellHeight++
+	}
+	if cellWidth < 2 || cellHeight < 2 || 2*cellWidth > width || 2*cellHeight > height {
+		return false
+	}
+
+	second := buckets[cellWidth]
+	mismatches := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			want := first
+			if (x/cellWidth+y/cellHeight)%2 == 1 {
+				want = second
+			}
+			if buckets[y*width+x] != want {
+				mismatches++
+			}
+		}
+	}
+
+	return float64(mismatches) <= testPatternNoise*float64(width*height)
+}
+
+// Finds bands of rows holding non-background pixels, then splits each band into
+// blocks wherever a gap of at least the band height separates them, like the space between words and pictograms
+func textAreas(buckets []bucket, width, height int, background bucket) []Rect {
+	lit := func(x, y int) bool {
+		return buckets[y*width+x] != background
+	}
+
+	rowLit := make([]bool, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width && !rowLit[y]; x++ {
+			rowLit[y] = lit(x, y)
+		}
+	}
+
+	areas := make([]Rect, 0)
+	for y := 0; y < height; {
+		if !rowLit[y] {
+			y++
+			continue
+		}
+
+		top := y
+		for y < height && rowLit[y] {
+			y++
+		}
+		bandHeight := y - top
+
+		colLit := make([]bool, width)
+		for x := 0; x < width; x++ {
+			for row := top; row < y && !colLit[x]; row++ {
+				colLit[x] = lit(x, row)
+			}
+		}
+
+		start, lastLit := -1, -1
+		for x := 0; x <= width; x++ {
+			if x < width && colLit[x] {
+				if start == -1 {
+					start = x
+				}
+				lastLit = x
+				continue
+			}
+
+			if start != -1 && (x == width || x-lastLit > bandHeight) {
+				areas = append(areas, Rect{X: start, Y: top, Width: lastLit - start + 1, Height: bandHeight})
+				start = -1
+			}
+		}
+	}
+
+	return areas
+}
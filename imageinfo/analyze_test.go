@@ -0,0 +1,142 @@
+package imageinfo
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func fill(img draw.Image, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func TestAnalyze(t *testing.T) {
+	amber := color.RGBA{240, 160, 0, 255}
+	red := color.RGBA{220, 20, 20, 255}
+
+	// Two lines of amber "text" with a red pictogram on the right of the first line
+	img := image.NewRGBA(image.Rect(0, 0, 200, 60))
+	fill(img, img.Bounds(), color.Black)
+	fill(img, image.Rect(10, 10, 40, 20), amber)
+	fill(img, image.Rect(44, 10, 80, 20), amber)
+	fill(img, image.Rect(150, 5, 170, 25), red)
+	fill(img, image.Rect(10, 35, 90, 45), amber)
+
+	analysis := Analyze(img)
+
+	if !analysis.HasAmber || !analysis.HasRed {
+		t.Errorf("Expected amber and red areas, got %+v", analysis)
+	}
+	if analysis.Blank || analysis.Black || analysis.TestPattern {
+		t.Errorf("Expected the image not to be blank or a test pattern")
+	}
+	if analysis.DominantColors[0] != "#101010" || len(analysis.DominantColors) != 3 {
+		t.Errorf("Expected black and two other dominant colors, got %v", analysis.DominantColors)
+	}
+
+	expected := []Rect{
+		{X: 10, Y: 5, Width: 70, Height: 20},
+		{X: 150, Y: 5, Width: 20, Height: 20},
+		{X: 10, Y: 35, Width: 80, Height: 10},
+	}
+	if !reflect.DeepEqual(analysis.TextAreas, expected) {
+		t.Errorf("Expected text areas %v, got %v", expected, analysis.TextAreas)
+	}
+}
+
+func TestAnalyzeBlank(t *testing.T) {
+	black := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	fill(black, black.Bounds(), color.Black)
+
+	analysis := Analyze(black)
+	if !analysis.Blank || !analysis.Black || len(analysis.TextAreas) != 0 {
+		t.Errorf("Expected a black image, got %+v", analysis)
+	}
+
+	// Transparent images show the background, so they are black as well
+	if analysis := Analyze(image.NewRGBA(image.Rect(0, 0, 100, 50))); !analysis.Black {
+		t.Errorf("Expected a transparent image to be black, got %+v", analysis)
+	}
+
+	white := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	fill(white, white.Bounds(), color.White)
+	if analysis := Analyze(white); !analysis.Blank || analysis.Black {
+		t.Errorf("Expected a blank image that isn't black, got %+v", analysis)
+	}
+}
+
+func TestAnalyzeTestPattern(t *testing.T) {
+	bars := image.NewRGBA(image.Rect(0, 0, 120, 40))
+	for i, c := range []color.Color{color.White, color.RGBA{255, 255, 0, 255}, color.RGBA{0, 255, 255, 255}, color.RGBA{0, 255, 0, 255}} {
+		fill(bars, image.Rect(i*30, 0, i*30+30, 40), c)
+	}
+
+	if analysis := Analyze(bars); !analysis.TestPattern || analysis.Blank {
+		t.Errorf("Expected color bars to be a test pattern, got %+v", analysis)
+	}
+
+	checkerboard := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			if (x/4+y/4)%2 == 0 {
+				checkerboard.Set(x, y, color.White)
+			} else {
+				checkerboard.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	if analysis := Analyze(checkerboard); !analysis.TestPattern {
+		t.Errorf("Expected a checkerboard to be a test pattern, got %+v", analysis)
+	}
+
+	horizontal := image.NewRGBA(image.Rect(0, 0, 120, 60))
+	for i, c := range []color.Color{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}} {
+		fill(horizontal, image.Rect(0, i*20, 120, i*20+20), c)
+	}
+
+	if analysis := Analyze(horizontal); !analysis.TestPattern {
+		t.Errorf("Expected horizontal bars to be a test pattern, got %+v", analysis)
+	}
+}
+
+func TestAnalyzeNoTestPattern(t *testing.T) {
+	// A photo and a rendered text panel, as sent by actual DRIPs
+	for _, name := range []string{"testdata/photo.png", "testdata/text.png"} {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if analysis := Analyze(img); analysis.TestPattern {
+			t.Errorf("Expected %v not to be a test pattern, got %+v", name, analysis)
+		}
+	}
+
+	// Bars need saturated colors
+	grey := image.NewRGBA(image.Rect(0, 0, 120, 40))
+	for i, c := range []color.Color{color.White, color.Gray{170}, color.Gray{85}, color.Black} {
+		fill(grey, image.Rect(i*30, 0, i*30+30, 40), c)
+	}
+	if analysis := Analyze(grey); analysis.TestPattern {
+		t.Errorf("Expected grey bars not to be a test pattern, got %+v", analysis)
+	}
+
+	// A pictogram next to a wide colored area isn't a set of bars
+	uneven := image.NewRGBA(image.Rect(0, 0, 120, 40))
+	fill(uneven, image.Rect(0, 0, 100, 40), color.RGBA{0, 0, 255, 255})
+	fill(uneven, image.Rect(100, 0, 110, 40), color.RGBA{255, 255, 0, 255})
+	fill(uneven, image.Rect(110, 0, 120, 40), color.RGBA{255, 0, 0, 255})
+	if analysis := Analyze(uneven); analysis.TestPattern {
+		t.Errorf("Expected uneven bars not to be a test pattern, got %+v", analysis)
+	}
+}
@@ -0,0 +1,127 @@
+// Package imageinfo extracts metadata from DRIP images
+package imageinfo
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+	"strconv"
+)
+
+// Perceptual hash of an image, similar images have hashes that differ in few bits
+type Hash uint64
+
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseUint(string(text), 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid perceptual hash %q", text)
+	}
+	*h = Hash(v)
+	return nil
+}
+
+// Number of bits that differ between two hashes, 0 to 64
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Averages the luminance of img over a w by h grid
+func shrinkGray(img image.Image, w, h int) []float64 {
+	bounds := img.Bounds()
+	sums := make([]float64, w*h)
+	counts := make([]int, w*h)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		cellY := (y - bounds.Min.Y) * h / bounds.Dy()
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cellX := (x - bounds.Min.X) * w / bounds.Dx()
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+
+			sums[cellY*w+cellX] += float64(gray.Y)
+			counts[cellY*w+cellX]++
+		}
+	}
+
+	for i := range sums {
+		if counts[i] > 0 {
+			sums[i] /= float64(counts[i])
+		}
+	}
+
+	return sums
+}
+
+// Difference hash: shrinks the image to 9x8 and records whether each cell is brighter than its right neighbour
+// Images smaller than 9x8 pixels are stretched by reusing cells
+func DHash(img image.Image) Hash {
+	if img.Bounds().Empty() {
+		return 0
+	}
+
+	cells := shrinkGray(img, 9, 8)
+
+	var hash Hash
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if cells[y*9+x] > cells[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// Groups hashes that are within maxDistance of each other, directly or through other hashes
+// Returns the group of every hash, numbered in order of first appearance
+func Group(hashes []Hash, maxDistance int) []int {
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if Distance(hashes[i], hashes[j]) <= maxDistance {
+				a, b := find(i), find(j)
+				if a < b {
+					parent[b] = a
+				} else {
+					parent[a] = b
+				}
+			}
+		}
+	}
+
+	groups := make([]int, len(hashes))
+	numbers := make(map[int]int)
+	for i := range hashes {
+		root := find(i)
+		number, found := numbers[root]
+		if !found {
+			number = len(numbers)
+			numbers[root] = number
+		}
+		groups[i] = number
+	}
+
+	return groups
+}
@@ -0,0 +1,70 @@
+package imageinfo
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// Draws a white rectangle at the given position on a black image
+func panel(w, h int, rect image.Rectangle) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if image.Pt(x, y).In(rect) {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	original := panel(180, 80, image.Rect(20, 10, 90, 60))
+	// Same pictogram at a different size, as sent by another panel type
+	scaled := panel(90, 40, image.Rect(10, 5, 45, 30))
+	different := panel(180, 80, image.Rect(100, 20, 170, 70))
+
+	if d := Distance(DHash(original), DHash(scaled)); d > 4 {
+		t.Errorf("Expected a scaled image to hash nearly the same, distance is %v", d)
+	}
+
+	if d := Distance(DHash(original), DHash(different)); d < 10 {
+		t.Errorf("Expected a different image to hash differently, distance is %v", d)
+	}
+
+	if DHash(image.NewRGBA(image.Rect(0, 0, 0, 0))) != 0 {
+		t.Errorf("Expected an empty image to hash to 0")
+	}
+
+	// Images smaller than the hash grid still work
+	DHash(panel(3, 2, image.Rect(0, 0, 1, 1)))
+}
+
+func TestHashJson(t *testing.T) {
+	str, err := json.Marshal(Hash(0xf0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(str) != `"00000000000000f0"` {
+		t.Errorf("Unexpected JSON %v", string(str))
+	}
+
+	var hash Hash
+	if err := json.Unmarshal(str, &hash); err != nil || hash != 0xf0 {
+		t.Errorf("Expected to read back 0xf0, got %v %v", hash, err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	hashes := []Hash{0b0000, 0b1111 << 20, 0b0001, 0b0011, 0b1110 << 20, 0xffffffff}
+	groups := Group(hashes, 1)
+
+	if expected := []int{0, 1, 0, 0, 1, 2}; !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected groups %v, got %v", expected, groups)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hunternl/trafficmap/imageinfo"
+)
+
+// Perceptual hashes this many bits apart or less are considered the same image
+const nearDuplicateDistance = 6
+
+// Lists every distinct image with the DRIPs showing it, most shown first
+// Near-duplicates share a group, numbered from the most shown image down
+func distinctImages(drips []Drip, updated time.Time) ApiImageList {
+	byHash := make(map[string]*ApiDistinctImage)
+	for _, drip := range drips {
+		if !drip.hasImage() {
+			continue
+		}
+
+		image, found := byHash[drip.ImageHash]
+		if !found {
+			image = &ApiDistinctImage{ApiImage: *toApiDrip(drip).Image, DripIds: make([]string, 0, 1)}
+			byHash[drip.ImageHash] = image
+		}
+		image.Count++
+		image.DripIds = append(image.DripIds, drip.Id)
+	}
+
+	out := ApiImageList{
+		DateUpdated: updated,
+		Images:      make([]ApiDistinctImage, 0, len(byHash)),
+		Groups:      make([]ApiImageGroup, 0),
+	}
+	for _, image := range byHash {
+		sort.Strings(image.DripIds)
+		out.Images = append(out.Images, *image)
+	}
+
+	sort.Slice(out.Images, func(i, j int) bool {
+		if out.Images[i].Count != out.Images[j].Count {
+			return out.Images[i].Count > out.Images[j].Count
+		}
+		return out.Images[i].Hash < out.Images[j].Hash
+	})
+
+	hashes := make([]imageinfo.Hash, len(out.Images))
+	for i, image := range out.Images {
+		hashes[i] = image.PerceptualHash
+	}
+
+	for i, group := range imageinfo.Group(hashes, nearDuplicateDistance) {
+		out.Images[i].Group = group
+		if group == len(out.Groups) {
+			out.Groups = append(out.Groups, ApiImageGroup{Group: group})
+		}
+		out.Groups[group].Images++
+		out.Groups[group].Count += out.Images[i].Count
+	}
+
+	sort.SliceStable(out.Groups, func(i, j int) bool {
+		return out.Groups[i].Count > out.Groups[j].Count
+	})
+
+	return out
+}
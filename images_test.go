@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDistinctImages(t *testing.T) {
+	image := []byte{1}
+	drips := []Drip{
+		{Id: "ID_1", image: image, ImageHash: "aaaa", PerceptualHash: 0b0000},
+		{Id: "ID_2", image: image, ImageHash: "bbbb", PerceptualHash: 0b0001},
+		{Id: "ID_3", image: image, ImageHash: "bbbb", PerceptualHash: 0b0001},
+		{Id: "ID_4", image: image, ImageHash: "cccc", PerceptualHash: 0xffff << 32},
+		{Id: "ID_5", image: image, ImageHash: "dddd", PerceptualHash: 0xffff << 32},
+		{Id: "ID_6"},
+	}
+
+	list := distinctImages(drips, time.Time{})
+
+	assert(t, len(list.Images), 4)
+	assert(t, list.Images[0].Hash, "bbbb")
+	assert(t, list.Images[0].Count, 2)
+	assert(t, list.Images[0].Url, "/img/bbbb.png")
+	assert(t, len(list.Images[0].DripIds), 2)
+
+	// aaaa is a near-duplicate of bbbb, cccc and dddd are visually the same
+	assert(t, list.Images[1].Hash, "aaaa")
+	assert(t, list.Images[1].Group, list.Images[0].Group)
+	assert(t, list.Images[2].Group, list.Images[3].Group)
+
+	assert(t, len(list.Groups), 2)
+	assert(t, list.Groups[0].Count, 3)
+	assert(t, list.Groups[0].Images, 2)
+	assert(t, list.Groups[1].Count, 2)
+}
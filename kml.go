@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hunternl/trafficmap/coordinate"
+)
+
+// KML document for Google Earth, only the elements we use are modelled
+
+type kmlIcon struct {
+	Href string `xml:"href"`
+}
+
+type kmlIconStyle struct {
+	Scale float64 `xml:"scale"`
+	Icon  kmlIcon `xml:"Icon"`
+}
+
+type kmlStyle struct {
+	IconStyle kmlIconStyle `xml:"IconStyle"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlPlacemark struct {
+	Id          string    `xml:"id,attr"`
+	Name        string    `xml:"name"`
+	Description kmlCData  `xml:"description"`
+	Style       *kmlStyle `xml:"Style,omitempty"`
+	Point       kmlPoint  `xml:"Point"`
+}
+
+type kmlCData struct {
+	Text string `xml:",cdata"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlDocument struct {
+	XMLName xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Name    string      `xml:"Document>name"`
+	Folders []kmlFolder `xml:"Document>Folder"`
+}
+
+// Folder for DRIPs without a parsed road
+const kmlUnknownRoad = "Onbekende weg"
+
+// Human readable road position, e.g. "A12 R 23.4 km"
+func roadPosition(d Drip) string {
+	parts := make([]string, 0, 3)
+	if d.RoadId != "" {
+		parts = append(parts, d.RoadId)
+	}
+	if d.RoadSide != "" {
+		parts = append(parts, d.RoadSide)
+	}
+	if d.RoadOffset >= 0 {
+		parts = append(parts, strconv.FormatFloat(float64(d.RoadOffset)/1000, 'f', 1, 64)+" km")
+	}
+	return strings.Join(parts, " ")
+}
+
+// imageBase is prepended to image paths, Google Earth needs absolute URLs
+func kmlPlacemarkFor(d Drip, imageBase string) kmlPlacemark {
+	description := &strings.Builder{}
+	fmt.Fprintf(description, "<p>%v</p>", html.EscapeString(d.Organization))
+	if position := roadPosition(d); position != "" {
+		fmt.Fprintf(description, "<p>%v</p>", html.EscapeString(position))
+	}
+	if len(d.TextLines) > 0 {
+		lines := make([]string, len(d.TextLines))
+		for i, line := range d.TextLines {
+			lines[i] = html.EscapeString(line)
+		}
+		fmt.Fprintf(description, "<p>%v</p>", strings.Join(lines, "<br>"))
+	}
+
+	placemark := kmlPlacemark{
+		Id:    d.Id,
+		Name:  d.Name,
+		Point: kmlPoint{Coordinates: fmt.Sprintf("%v,%v", d.Longitude, d.Latitude)},
+	}
+
+	if d.hasMarkerImage() {
+		// Content addressed, so the image is served directly instead of through the /images/ redirect
+		href := imageBase + contentImagePath(d.ImageHash, "png")
+		fmt.Fprintf(description, `<img src="%v" width="%v" height="%v">`, href, d.ImageWidth, d.ImageHeight)
+		placemark.Style = &kmlStyle{IconStyle: kmlIconStyle{Scale: 1, Icon: kmlIcon{Href: href}}}
+	}
+
+	placemark.Description = kmlCData{Text: description.String()}
+
+	return placemark
+}
+
+// Builds one folder per road, sorted by road and offset, DRIPs without a position are left out
+func buildKml(drips []Drip, imageBase string) kmlDocument {
+	roads := make(map[string][]Drip)
+	for _, drip := range drips {
+		if drip.CoordinateStatus == coordinate.StatusInvalid {
+			continue
+		}
+		roadId := drip.RoadId
+		if roadId == "" {
+			roadId = kmlUnknownRoad
+		}
+		roads[roadId] = append(roads[roadId], drip)
+	}
+
+	roadIds := make([]string, 0, len(roads))
+	for roadId := range roads {
+		roadIds = append(roadIds, roadId)
+	}
+	sort.Strings(roadIds)
+
+	doc := kmlDocument{Name: "DRIPs", Folders: make([]kmlFolder, 0, len(roadIds))}
+	for _, roadId := range roadIds {
+		roadDrips := roads[roadId]
+		sortByOffset(roadDrips)
+
+		folder := kmlFolder{Name: roadId, Placemarks: make([]kmlPlacemark, len(roadDrips))}
+		for i, drip := range roadDrips {
+			folder.Placemarks[i] = kmlPlacemarkFor(drip, imageBase)
+		}
+		doc.Folders = append(doc.Folders, folder)
+	}
+
+	return doc
+}
+
+func handleKml(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+
+		serv.Lock()
+		doc := buildKml(serv.DripsSlice, scheme+"://"+r.Host)
+		serv.Unlock()
+
+		str, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.google-earth.kml+xml")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"drips.kml\"")
+		w.Write([]byte(xml.Header))
+		w.Write(str)
+	})
+}
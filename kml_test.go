@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestKml(t *testing.T) {
+	serv := newTestServ(t)
+	mux := createMux(serv)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "http://example.com/drips.kml", nil))
+
+	assert(t, recorder.Code, 200)
+
+	var doc kmlDocument
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	placemarks := make(map[string]kmlPlacemark)
+	for _, folder := range doc.Folders {
+		for _, placemark := range folder.Placemarks {
+			placemarks[placemark.Id] = placemark
+		}
+	}
+
+	drip2, found := placemarks["ID_2"]
+	if !found {
+		t.Fatal("Expected a placemark for ID_2")
+	}
+
+	href := "http://example.com" + contentImagePath(serv.dripsMap["ID_2"].ImageHash, "png")
+	if drip2.Style == nil || drip2.Style.IconStyle.Icon.Href != href {
+		t.Errorf("Expected the DRIP image as icon, got %+v", drip2.Style)
+	}
+
+	if !strings.Contains(drip2.Description.Text, `<img src="`+href+`"`) {
+		t.Errorf("Expected the DRIP image in the balloon, got %v", drip2.Description.Text)
+	}
+
+	if !strings.HasPrefix(drip2.Point.Coordinates, "4.") {
+		t.Errorf("Expected longitude first, got %v", drip2.Point.Coordinates)
+	}
+}
+
+func TestRoadPosition(t *testing.T) {
+	assert(t, roadPosition(Drip{RoadId: "A12", RoadSide: "R", RoadOffset: 23400}), "A12 R 23.4 km")
+	assert(t, roadPosition(Drip{RoadId: "N201", RoadOffset: -1}), "N201")
+}
@@ -6,42 +6,84 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/hunternl/trafficmap/coordinate"
+	"github.com/hunternl/trafficmap/description"
+	"github.com/hunternl/trafficmap/imageinfo"
+	"github.com/hunternl/trafficmap/ndw"
+	"github.com/hunternl/trafficmap/timeline"
+	"github.com/hunternl/trafficmap/traveltime"
 )
 
 const UpdateInterval = time.Minute * 5
 
+// How far back travel times are kept per DRIP
+const TravelTimeRetention = time.Hour * 24
+
+// How far back displayed images are kept per DRIP, unless they're kept in a -history directory
+const TimelineRetention = time.Hour * 24
+
 type DripServ struct {
 	sync.Mutex
-	dripsMap   map[string]Drip
-	DripsSlice []Drip `json:"drips"`
-	LastUpdate time.Time
+	dripsMap map[string]Drip
+	// PNG bytes of every image currently shown, by ImageHash
+	imagesByHash map[string][]byte
+	travelTimes  *traveltime.History
+	timeline     *timeline.Store
+	updates      *updateBroadcaster
+	sprites      *spriteSheet
+	variants     *variantCache
+	DripsSlice   []Drip `json:"drips"`
+	LastUpdate   time.Time
 }
 
 func newServ() DripServ {
 	return DripServ{
-		dripsMap:   make(map[string]Drip),
-		DripsSlice: make([]Drip, 0),
+		dripsMap:     make(map[string]Drip),
+		imagesByHash: make(map[string][]byte),
+		travelTimes:  traveltime.NewHistory(int(TravelTimeRetention / UpdateInterval)),
+		timeline:     timeline.NewStore(TimelineRetention),
+		updates:      newBroadcaster(),
+		variants:     newVariantCache(),
+		DripsSlice:   make([]Drip, 0),
 	}
 }
 
 type Drip struct {
-	Id           string `json:"id"`
-	image        []byte
-	Lat          string   `json:"lat"`
-	Lon          string   `json:"lon"`
-	Name         string   `json:"name"`
-	ImageWidth   int      `json:"imageWidth"`
-	ImageHeight  int      `json:"imageHeight"`
-	Working      bool     `json:"working"`
-	RoadId       string   `json:"roadId"`
-	RoadSide     string   `json:"roadSide"`
-	RoadOffset   int      `json:"roadOffset"`
-	Organization string   `json:"organization"`
-	TextLines    []string `json:"text"`
+	Id               string `json:"id"`
+	image            []byte
+	Lat              string              `json:"lat"`
+	Lon              string              `json:"lon"`
+	Latitude         float64             `json:"-"`
+	Longitude        float64             `json:"-"`
+	CoordinateStatus coordinate.Status   `json:"coordinateStatus"`
+	RdX              float64             `json:"rdX"`
+	RdY              float64             `json:"rdY"`
+	Name             string              `json:"name"`
+	ImageWidth       int                 `json:"imageWidth"`
+	ImageHeight      int                 `json:"imageHeight"`
+	ImageHash        string              `json:"imageHash"`
+	PerceptualHash   imageinfo.Hash      `json:"perceptualHash"`
+	ImageAnalysis    *imageinfo.Analysis `json:"imageAnalysis"`
+	ImageRendered    bool                `json:"imageRendered"`
+	DisplayState     DisplayState        `json:"displayState"`
+	Working          bool                `json:"working"`
+	RoadId           string              `json:"roadId"`
+	RoadSide         string              `json:"roadSide"`
+	RoadOffset       int                 `json:"roadOffset"`
+	Carriageway      string              `json:"carriageway"`
+	Junction         string              `json:"junction"`
+	HectometerLetter string              `json:"hectometerLetter"`
+	Organization     string              `json:"organization"`
+	OrganizationCode string              `json:"organizationCode"`
+	TextLines        []string            `json:"text"`
+	Routes           []traveltime.Route  `json:"routes,omitempty"`
 }
 
+// If the DRIP sends an image of its own, images rendered from its text don't count
 func (d *Drip) hasImage() bool {
 	if d.image == nil {
 		return false
@@ -51,14 +93,20 @@ func (d *Drip) hasImage() bool {
 		return false
 	}
 
-	return true
+	return !d.ImageRendered
+}
+
+// If there's an image to show for the DRIP, either sent by it or rendered from its text
+func (d *Drip) hasMarkerImage() bool {
+	return len(d.image) > 0
 }
 
 // If there's any interesting text
 // Interesting = if it has any line with more than 1 character
 func (d *Drip) hasText() bool {
 	for _, v := range d.TextLines {
-		if len(v) > 1 {
+		// Panels pad their lines with spaces, so "   " holds no text
+		if len(strings.TrimSpace(v)) > 1 {
 			return true
 		}
 	}
@@ -78,11 +126,27 @@ func main() {
 	sourceUrl := flag.String("sourceURL", "http://opendata.ndw.nu/", "Full URL to retrieve the source data from")
 	downloadOnly := flag.Bool("download", false, "Only download images and quit")
 	outDir := flag.String("outdir", ".", "Output directory for files")
+	exportFile := flag.String("export", "", "Only export the current DRIPs to the given .csv, .parquet or .geojson file and quit")
+	exportCrs := flag.String("crs", "", "Coordinate system of the export, wgs84 or rd; CSV and Parquet include both when empty")
 	host := flag.String("host", "0.0.0.0", "Network addres to use")
 	port := flag.Int("port", 3000, "Port to serve http on")
+	grpcPort := flag.Int("grpcport", 0, "Port to serve gRPC on, disabled when 0")
+	organizationsFile := flag.String("organizations", "", "JSON file replacing the built-in organization registry")
+	historyDir := flag.String("history", "", "Directory to keep every displayed image in, for timelines beyond the last day")
+	timelineId := flag.String("timeline", "", "Only write an animated timeline of the given DRIP from -history to -outdir and quit")
+	timelineFormat := flag.String("format", "gif", "Timeline format, gif or png (APNG)")
+	timelineFrom := flag.String("from", "", "RFC 3339 start of the timeline, by default a day before -to")
+	timelineTo := flag.String("to", "", "RFC 3339 end of the timeline, by default now")
 
 	flag.Parse()
 
+	if *organizationsFile != "" {
+		err := loadOrganizations(*organizationsFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
 	if *downloadOnly {
 		error := outputImages(*sourceUrl, *outDir)
 		if error != nil {
@@ -91,7 +155,36 @@ func main() {
 		return
 	}
 
+	if *timelineId != "" {
+		from, to, err := parseTimelineRange(*timelineFrom, *timelineTo)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		err = writeTimeline(*historyDir, *timelineId, *timelineFormat, from, to, *outDir)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if *exportFile != "" {
+		err := exportDrips(*sourceUrl, *exportFile, *exportCrs)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	serv := newServ()
+	if *historyDir != "" {
+		store, err := timeline.Open(*historyDir, TimelineRetention)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		serv.timeline = store
+	}
+
 	ticker := time.NewTicker(UpdateInterval)
 	err := updateDrips(*sourceUrl, &serv)
 	if err != nil {
@@ -100,6 +193,10 @@ func main() {
 	fmt.Printf("Succesfully got data from %v\n", *sourceUrl)
 	go update(*sourceUrl, ticker.C, &serv)
 
+	if *grpcPort != 0 {
+		go ServeGrpc(*host, *grpcPort, &serv)
+	}
+
 	// placeDripsFile()
 	ServeData(*host, *port, &serv)
 }
@@ -127,8 +224,63 @@ func main() {
 
 // }
 
+func loadOrganizations(fileName string) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("error reading organization registry: %w", err)
+	}
+
+	registry, err := description.LoadRegistry(data)
+	if err != nil {
+		return err
+	}
+
+	description.Organizations = registry
+	return nil
+}
+
+func exportDrips(baseUrl, fileName, crsName string) error {
+	var crs coordinate.CRS
+	if crsName != "" {
+		var err error
+		crs, err = coordinate.ParseCRS(crsName)
+		if err != nil {
+			return err
+		}
+	}
+
+	serv := newServ()
+	err := updateDrips(baseUrl, &serv)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating export file: %w", err)
+	}
+	defer file.Close()
+
+	if filepath.Ext(fileName) == ".geojson" {
+		if crs == "" {
+			crs = coordinate.WGS84
+		}
+		err = writeGeoJson(file, serv.DripsSlice, serv.LastUpdate, crs)
+	} else {
+		columns, rows := dripTable(serv.DripsSlice, serv.LastUpdate, crs)
+		err = writeExport(file, fileName, columns, rows)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %v DRIPs to %v\n", len(serv.DripsSlice), fileName)
+
+	return file.Close()
+}
+
 func outputImages(baseUrl, outDir string) error {
-	dripsFile, err := getFile(baseUrl, dripStatusFile, true)
+	dripsFile, err := ndw.GetFile(baseUrl, ndw.StatusFile, true)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,159 @@
+// Package mvt encodes point layers as Mapbox Vector Tiles (version 2.1)
+package mvt
+
+import (
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Tile coordinate space of a layer
+const Extent = 4096
+
+type Feature struct {
+	// Position within the tile, 0 to Extent, or just outside it for features in the tile's buffer
+	X, Y int
+	// Values are string, bool, int, int64 or float64
+	Properties map[string]any
+}
+
+type Layer struct {
+	Name     string
+	Features []Feature
+}
+
+// Field numbers from vector_tile.proto
+const (
+	tileLayers = 3
+
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+	layerVersion  = 15
+
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueDouble = 3
+	valueSint   = 6
+	valueBool   = 7
+)
+
+const geomTypePoint = 1
+
+// MoveTo with a count of 1
+const commandMoveTo = 1<<3 | 1
+
+func zigzag(v int64) uint64 {
+	return protowire.EncodeZigZag(v)
+}
+
+func appendValue(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case string:
+		b = protowire.AppendTag(b, valueString, protowire.BytesType)
+		return protowire.AppendString(b, v)
+	case bool:
+		b = protowire.AppendTag(b, valueBool, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case int:
+		b = protowire.AppendTag(b, valueSint, protowire.VarintType)
+		return protowire.AppendVarint(b, zigzag(int64(v)))
+	case int64:
+		b = protowire.AppendTag(b, valueSint, protowire.VarintType)
+		return protowire.AppendVarint(b, zigzag(v))
+	case float64:
+		b = protowire.AppendTag(b, valueDouble, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(v))
+	}
+	return b
+}
+
+func appendPacked(b []byte, num protowire.Number, values []uint64) []byte {
+	packed := make([]byte, 0)
+	for _, v := range values {
+		packed = protowire.AppendVarint(packed, v)
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
+
+func encodeLayer(layer Layer) []byte {
+	keys := make([]string, 0)
+	keyIndex := make(map[string]int)
+	values := make([]any, 0)
+	valueIndex := make(map[any]int)
+
+	b := protowire.AppendTag(nil, layerVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, 2)
+	b = protowire.AppendTag(b, layerName, protowire.BytesType)
+	b = protowire.AppendString(b, layer.Name)
+
+	for _, feature := range layer.Features {
+		// Sorted keys keep the output stable
+		tags := make([]uint64, 0, len(feature.Properties)*2)
+		for _, key := range sortedKeys(feature.Properties) {
+			value := feature.Properties[key]
+
+			k, found := keyIndex[key]
+			if !found {
+				k = len(keys)
+				keyIndex[key] = k
+				keys = append(keys, key)
+			}
+
+			v, found := valueIndex[value]
+			if !found {
+				v = len(values)
+				valueIndex[value] = v
+				values = append(values, value)
+			}
+
+			tags = append(tags, uint64(k), uint64(v))
+		}
+
+		f := appendPacked(nil, featureTags, tags)
+		f = protowire.AppendTag(f, featureType, protowire.VarintType)
+		f = protowire.AppendVarint(f, geomTypePoint)
+		f = appendPacked(f, featureGeometry, []uint64{commandMoveTo, zigzag(int64(feature.X)), zigzag(int64(feature.Y))})
+
+		b = protowire.AppendTag(b, layerFeatures, protowire.BytesType)
+		b = protowire.AppendBytes(b, f)
+	}
+
+	for _, key := range keys {
+		b = protowire.AppendTag(b, layerKeys, protowire.BytesType)
+		b = protowire.AppendString(b, key)
+	}
+
+	for _, value := range values {
+		b = protowire.AppendTag(b, layerValues, protowire.BytesType)
+		b = protowire.AppendBytes(b, appendValue(nil, value))
+	}
+
+	b = protowire.AppendTag(b, layerExtent, protowire.VarintType)
+	return protowire.AppendVarint(b, Extent)
+}
+
+func Encode(layers ...Layer) []byte {
+	b := make([]byte, 0)
+	for _, layer := range layers {
+		b = protowire.AppendTag(b, tileLayers, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeLayer(layer))
+	}
+	return b
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package mvt
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Returns the fields of a message by number, in order
+func decode(t *testing.T, b []byte) map[protowire.Number][]any {
+	t.Helper()
+
+	out := make(map[protowire.Number][]any)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		var v any
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		default:
+			t.Fatalf("Unexpected wire type %v", typ)
+		}
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		out[num] = append(out[num], v)
+	}
+	return out
+}
+
+func unpack(t *testing.T, b []byte) []uint64 {
+	out := make([]uint64, 0)
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		out = append(out, v)
+		b = b[n:]
+	}
+	return out
+}
+
+func TestEncode(t *testing.T) {
+	tile := Encode(Layer{
+		Name: "drips",
+		Features: []Feature{
+			{X: 10, Y: 20, Properties: map[string]any{"id": "ID_1", "working": true}},
+			{X: 30, Y: 40, Properties: map[string]any{"id": "ID_2", "working": true}},
+		},
+	})
+
+	layers := decode(t, tile)[tileLayers]
+	if len(layers) != 1 {
+		t.Fatalf("Expected 1 layer, got %v", len(layers))
+	}
+
+	layer := decode(t, layers[0].([]byte))
+	if string(layer[layerName][0].([]byte)) != "drips" || layer[layerVersion][0] != uint64(2) {
+		t.Errorf("Unexpected layer header %v", layer)
+	}
+
+	if len(layer[layerKeys]) != 2 || len(layer[layerValues]) != 3 {
+		t.Errorf("Expected keys and values to be shared, got %v keys and %v values", len(layer[layerKeys]), len(layer[layerValues]))
+	}
+
+	feature := decode(t, layer[layerFeatures][1].([]byte))
+	geometry := unpack(t, feature[featureGeometry][0].([]byte))
+	if len(geometry) != 3 || geometry[0] != commandMoveTo || protowire.DecodeZigZag(geometry[1]) != 30 || protowire.DecodeZigZag(geometry[2]) != 40 {
+		t.Errorf("Unexpected geometry %v", geometry)
+	}
+
+	// "id" sorts before "working", ID_2 is the third value
+	if tags := unpack(t, feature[featureTags][0].([]byte)); len(tags) != 4 || tags[0] != 0 || tags[1] != 2 || tags[2] != 1 || tags[3] != 1 {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+}
@@ -0,0 +1,98 @@
+// Package ndw retrieves and parses the DRIP files published on opendata.ndw.nu
+package ndw
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+const StatusFile = "DRIPS.xml.gz"
+const LocationFile = "LocatietabelDRIPS.xml.gz"
+
+// Get a file from the given base url, optionally decompressing it
+// BaseURL is parsed and only the host(+port) and path is used
+// protocol is always set to http and the given filename is appended
+func GetFile(baseUrl, filePath string, gZip bool) ([]byte, error) {
+	sourceURL, err := url.Parse(baseUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.Get("http://" + sourceURL.Host + path.Join("/", sourceURL.Path, filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("server responded with %v", response.Status)
+	}
+
+	var reader io.ReadCloser
+
+	if gZip {
+		reader, err = gzip.NewReader(response.Body)
+	} else {
+		reader = response.Body
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+type Location struct {
+	Id          string `xml:"id,attr"`
+	Description string `xml:"vmsRecord>vmsRecord>vmsDescription>values>value"`
+	Latitude    string `xml:"vmsRecord>vmsRecord>vmsLocation>locationForDisplay>latitude"`
+	Longitude   string `xml:"vmsRecord>vmsRecord>vmsLocation>locationForDisplay>longitude"`
+}
+
+// Used to parse XML directly into a map instead of a slice
+type LocationMap map[string]Location
+
+func (l *LocationMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r := Location{}
+	err := d.DecodeElement(&r, &start)
+	if err != nil {
+		return err
+	}
+
+	(*l)[r.Id] = r
+
+	return nil
+}
+
+func ParseLocations(locationFile []byte, expectedSize int) (LocationMap, error) {
+	locations := struct {
+		Locations LocationMap `xml:"Body>d2LogicalModel>payloadPublication>vmsUnitTable>vmsUnitRecord"`
+	}{
+		Locations: make(LocationMap, expectedSize),
+	}
+
+	err := xml.Unmarshal(locationFile, &locations)
+	if err != nil {
+		return nil, err
+	}
+
+	return locations.Locations, nil
+}
+
+// Retrieves and parses the location table
+func FetchLocations(baseUrl string) (LocationMap, error) {
+	file, err := GetFile(baseUrl, LocationFile, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve drip location file: %w", err)
+	}
+
+	return ParseLocations(file, 0)
+}
@@ -0,0 +1,47 @@
+package ndw
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestFetchLocations(t *testing.T) {
+	file, err := os.ReadFile("../testdata/vmsRecord.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	writer.Write(file)
+	writer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data/"+LocationFile {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	locations, err := FetchLocations(server.URL + "/data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(locations) != 3 {
+		t.Fatalf("Expected 3 locations, got %v", len(locations))
+	}
+	if locations["ID_1"].Description != "Description 1" || locations["ID_1"].Latitude != "52.1" {
+		t.Errorf("Unexpected location %+v", locations["ID_1"])
+	}
+
+	if _, err := FetchLocations(server.URL + "/elsewhere/"); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hunternl/trafficmap/description"
+)
+
+type OrganizationCount struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Counts DRIPs per organization, most DRIPs first
+// DRIPs without a known organization are counted under an empty code
+func countOrganizations(drips []Drip) []OrganizationCount {
+	counts := make(map[string]*OrganizationCount)
+
+	for _, org := range description.Organizations.All() {
+		counts[org.Code] = &OrganizationCount{Code: org.Code, Name: org.Name}
+	}
+
+	for _, drip := range drips {
+		count, found := counts[drip.OrganizationCode]
+		if !found {
+			count = &OrganizationCount{Code: drip.OrganizationCode, Name: drip.Organization}
+			counts[drip.OrganizationCode] = count
+		}
+		count.Count++
+	}
+
+	out := make([]OrganizationCount, 0, len(counts))
+	for _, count := range counts {
+		out = append(out, *count)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Code < out[j].Code
+	})
+
+	return out
+}
+
+func handleOrganizations(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serv.Lock()
+		defer serv.Unlock()
+
+		str, err := json.Marshal(countOrganizations(serv.DripsSlice))
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(str)
+	})
+}
@@ -0,0 +1,265 @@
+// Package parquet writes flat tables as Parquet files
+// Only what the exports need is supported: a single row group, plain encoding
+// and no compression, which any Parquet reader can load
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Int64
+	Double
+	Bool
+	// Stored as milliseconds since the Unix epoch
+	Timestamp
+)
+
+type Column struct {
+	Name string
+	Type Type
+	// Optional columns accept nil values
+	Optional bool
+}
+
+// Parquet physical types
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+)
+
+// Parquet converted types
+const (
+	convertedUtf8            = 0
+	convertedTimestampMillis = 9
+)
+
+const (
+	encodingPlain = 0
+	encodingRle   = 3
+)
+
+const magic = "PAR1"
+
+func (t Type) physical() int32 {
+	switch t {
+	case Int64, Timestamp:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	case Bool:
+		return physicalBoolean
+	}
+	return physicalByteArray
+}
+
+// Appends the plain encoding of v, booleans are handled separately as they are bit-packed
+func appendPlain(b []byte, t Type, v any) ([]byte, error) {
+	switch t {
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+		return append(b, s...), nil
+	case Int64:
+		i, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("expected int64, got %T", v)
+		}
+		return binary.LittleEndian.AppendUint64(b, uint64(i)), nil
+	case Double:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected float64, got %T", v)
+		}
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(f)), nil
+	case Timestamp:
+		ts, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("expected time.Time, got %T", v)
+		}
+		return binary.LittleEndian.AppendUint64(b, uint64(ts.UnixMilli())), nil
+	}
+	return nil, fmt.Errorf("unsupported type %v", t)
+}
+
+// Encodes definition levels (0 = null, 1 = present) as RLE runs with a bit width of 1
+func appendDefinitionLevels(b []byte, present []bool) []byte {
+	runs := make([]byte, 0)
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		runs = binary.AppendUvarint(runs, uint64(j-i)<<1)
+		if present[i] {
+			runs = append(runs, 1)
+		} else {
+			runs = append(runs, 0)
+		}
+		i = j
+	}
+
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(runs)))
+	return append(b, runs...)
+}
+
+// Encodes a column as a single data page, returning the page including its header
+func encodeColumn(column Column, index int, rows [][]any) ([]byte, error) {
+	page := make([]byte, 0)
+	present := make([]bool, len(rows))
+	bools := make([]bool, 0)
+	values := make([]byte, 0)
+
+	for i, row := range rows {
+		v := row[index]
+		if v == nil {
+			if !column.Optional {
+				return nil, fmt.Errorf("column %v: row %v is null", column.Name, i)
+			}
+			continue
+		}
+		present[i] = true
+
+		if column.Type == Bool {
+			bv, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("column %v: expected bool, got %T", column.Name, v)
+			}
+			bools = append(bools, bv)
+			continue
+		}
+
+		var err error
+		values, err = appendPlain(values, column.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %w", column.Name, err)
+		}
+	}
+
+	if column.Type == Bool {
+		packed := make([]byte, (len(bools)+7)/8)
+		for i, bv := range bools {
+			if bv {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		values = packed
+	}
+
+	if column.Optional {
+		page = appendDefinitionLevels(page, present)
+	}
+	page = append(page, values...)
+
+	dataPageHeader := &thriftWriter{}
+	dataPageHeader.i32(1, int32(len(rows)))
+	dataPageHeader.i32(2, encodingPlain)
+	dataPageHeader.i32(3, encodingRle)
+	dataPageHeader.i32(4, encodingRle)
+
+	header := &thriftWriter{}
+	header.i32(1, 0) // DATA_PAGE
+	header.i32(2, int32(len(page)))
+	header.i32(3, int32(len(page)))
+	header.structField(5, dataPageHeader)
+
+	return append(header.end(), page...), nil
+}
+
+func schemaElement(column Column) *thriftWriter {
+	element := &thriftWriter{}
+	element.i32(1, column.Type.physical())
+	if column.Optional {
+		element.i32(3, 1)
+	} else {
+		element.i32(3, 0)
+	}
+	element.string(4, column.Name)
+
+	switch column.Type {
+	case String:
+		element.i32(6, convertedUtf8)
+	case Timestamp:
+		element.i32(6, convertedTimestampMillis)
+	}
+
+	return element
+}
+
+// Writes rows as a Parquet file, every row holds one value per column in the same order
+// Values are string, int64, float64, bool or time.Time depending on the column type
+func Write(w io.Writer, columns []Column, rows [][]any) error {
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("row %v has %v values, expected %v", i, len(row), len(columns))
+		}
+	}
+
+	out := []byte(magic)
+
+	schema := []*thriftWriter{{}}
+	schema[0].string(4, "schema")
+	schema[0].i32(5, int32(len(columns)))
+
+	chunks := make([]*thriftWriter, len(columns))
+	totalSize := 0
+
+	for i, column := range columns {
+		schema = append(schema, schemaElement(column))
+
+		page, err := encodeColumn(column, i, rows)
+		if err != nil {
+			return err
+		}
+
+		offset := int64(len(out))
+		out = append(out, page...)
+		totalSize += len(page)
+
+		meta := &thriftWriter{}
+		meta.i32(1, column.Type.physical())
+		meta.i32List(2, []int32{encodingPlain, encodingRle})
+		meta.stringList(3, []string{column.Name})
+		meta.i32(4, 0) // UNCOMPRESSED
+		meta.i64(5, int64(len(rows)))
+		meta.i64(6, int64(len(page)))
+		meta.i64(7, int64(len(page)))
+		meta.i64(9, offset)
+
+		chunks[i] = &thriftWriter{}
+		chunks[i].i64(2, offset)
+		chunks[i].structField(3, meta)
+	}
+
+	rowGroup := &thriftWriter{}
+	rowGroup.structList(1, chunks)
+	rowGroup.i64(2, int64(totalSize))
+	rowGroup.i64(3, int64(len(rows)))
+
+	footer := &thriftWriter{}
+	footer.i32(1, 1)
+	footer.structList(2, schema)
+	footer.i64(3, int64(len(rows)))
+	footer.structList(4, []*thriftWriter{rowGroup})
+	footer.string(6, "trafficmap")
+
+	footerBytes := footer.end()
+	out = append(out, footerBytes...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(footerBytes)))
+	out = append(out, magic...)
+
+	_, err := w.Write(out)
+	return err
+}
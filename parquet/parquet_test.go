@@ -0,0 +1,174 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Minimal compact protocol reader, decoding structs into maps keyed by field id
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) byte() byte {
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := r.uvarint()
+		v := string(r.b[:n])
+		r.b = r.b[n:]
+		return v
+	case thriftList:
+		header := r.byte()
+		size := uint64(header >> 4)
+		if size == 15 {
+			size = r.uvarint()
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic("unsupported thrift type")
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	out := make(map[int16]any)
+	var lastId int16
+
+	for {
+		header := r.byte()
+		if header == 0 {
+			return out
+		}
+
+		id := lastId + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		lastId = id
+
+		out[id] = r.value(header & 0x0f)
+	}
+}
+
+func readFooter(t *testing.T, file []byte) map[int16]any {
+	t.Helper()
+
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatal("missing PAR1 magic")
+	}
+
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := file[len(file)-8-int(size) : len(file)-8]
+
+	return (&thriftReader{b: footer}).readStruct()
+}
+
+func TestWrite(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Type: String},
+		{Name: "lat", Type: Double, Optional: true},
+		{Name: "working", Type: Bool},
+		{Name: "offset", Type: Int64},
+		{Name: "time", Type: Timestamp},
+	}
+
+	updated := time.UnixMilli(1700000000000)
+	rows := [][]any{
+		{"ID_1", 52.1, true, int64(10), updated},
+		{"ID_2", nil, false, int64(-1), updated},
+		{"ID_3", 52.3, true, int64(30), updated},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, columns, rows); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+
+	footer := readFooter(t, file)
+	if footer[3] != int64(3) {
+		t.Errorf("Expected 3 rows, got %v", footer[3])
+	}
+
+	schema := footer[2].([]any)
+	names := make([]string, 0)
+	for _, element := range schema[1:] {
+		names = append(names, element.(map[int16]any)[4].(string))
+	}
+	if !reflect.DeepEqual(names, []string{"id", "lat", "working", "offset", "time"}) {
+		t.Errorf("Unexpected schema %v", names)
+	}
+
+	chunks := footer[4].([]any)[0].(map[int16]any)[1].([]any)
+
+	// Reads the values of a column chunk after its page header
+	pageData := func(column int) []byte {
+		meta := chunks[column].(map[int16]any)[3].(map[int16]any)
+		reader := &thriftReader{b: file[meta[9].(int64):]}
+		header := reader.readStruct()
+		return reader.b[:header[2].(int64)]
+	}
+
+	ids := pageData(0)
+	for _, expected := range []string{"ID_1", "ID_2", "ID_3"} {
+		size := binary.LittleEndian.Uint32(ids)
+		if got := string(ids[4 : 4+size]); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+		ids = ids[4+size:]
+	}
+
+	// Definition levels come first: runs of 1 present, 1 null, 1 present
+	lat := pageData(1)
+	levelsSize := binary.LittleEndian.Uint32(lat)
+	if !bytes.Equal(lat[4:4+levelsSize], []byte{2, 1, 2, 0, 2, 1}) {
+		t.Errorf("Unexpected definition levels %v", lat[4:4+levelsSize])
+	}
+	values := lat[4+levelsSize:]
+	if len(values) != 16 || math.Float64frombits(binary.LittleEndian.Uint64(values[8:])) != 52.3 {
+		t.Errorf("Unexpected lat values %v", values)
+	}
+
+	if working := pageData(2); !bytes.Equal(working, []byte{0b101}) {
+		t.Errorf("Unexpected working values %v", working)
+	}
+}
+
+func TestWriteRejectsNull(t *testing.T) {
+	err := Write(&bytes.Buffer{}, []Column{{Name: "id", Type: String}}, [][]any{{nil}})
+	if err == nil {
+		t.Error("Expected an error for a null in a required column")
+	}
+}
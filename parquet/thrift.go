@@ -0,0 +1,90 @@
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// Thrift compact protocol type ids, as used by the Parquet footer and page headers
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// Encodes a single Thrift struct in the compact protocol
+// Fields must be written in ascending id order
+type thriftWriter struct {
+	b      []byte
+	lastId int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	delta := id - t.lastId
+	if delta > 0 && delta <= 15 {
+		t.b = append(t.b, byte(delta)<<4|typ)
+	} else {
+		t.b = append(t.b, typ)
+		t.b = binary.AppendVarint(t.b, int64(id))
+	}
+	t.lastId = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.b = binary.AppendVarint(t.b, int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.b = binary.AppendVarint(t.b, v)
+}
+
+func (t *thriftWriter) string(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.b = binary.AppendUvarint(t.b, uint64(len(v)))
+	t.b = append(t.b, v...)
+}
+
+func (t *thriftWriter) structField(id int16, v *thriftWriter) {
+	t.fieldHeader(id, thriftStruct)
+	t.b = append(t.b, v.end()...)
+}
+
+func (t *thriftWriter) listHeader(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.b = append(t.b, byte(size)<<4|elemType)
+	} else {
+		t.b = append(t.b, 0xf0|elemType)
+		t.b = binary.AppendUvarint(t.b, uint64(size))
+	}
+}
+
+func (t *thriftWriter) i32List(id int16, v []int32) {
+	t.listHeader(id, thriftI32, len(v))
+	for _, i := range v {
+		t.b = binary.AppendVarint(t.b, int64(i))
+	}
+}
+
+func (t *thriftWriter) stringList(id int16, v []string) {
+	t.listHeader(id, thriftBinary, len(v))
+	for _, s := range v {
+		t.b = binary.AppendUvarint(t.b, uint64(len(s)))
+		t.b = append(t.b, s...)
+	}
+}
+
+func (t *thriftWriter) structList(id int16, v []*thriftWriter) {
+	t.listHeader(id, thriftStruct, len(v))
+	for _, s := range v {
+		t.b = append(t.b, s.end()...)
+	}
+}
+
+// Returns the encoded struct including its stop byte
+func (t *thriftWriter) end() []byte {
+	return append(t.b, 0)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type RoadMessage struct {
+	DripId     string   `json:"dripId"`
+	RoadSide   string   `json:"roadSide"`
+	RoadOffset int      `json:"roadOffset"`
+	TextLines  []string `json:"text"`
+}
+
+type RoadSummary struct {
+	RoadId     string `json:"roadId"`
+	Count      int    `json:"count"`
+	NonWorking int    `json:"nonWorking"`
+	// DRIPs whose DisplayState is active, also those showing only an image, ordered by RoadOffset
+	ActiveMessages []RoadMessage `json:"activeMessages"`
+}
+
+type RoadDetail struct {
+	RoadId string `json:"roadId"`
+	// DRIPs per side ("L", "R" or "" if unknown), ordered by RoadOffset
+	Sides map[string][]Drip `json:"sides"`
+}
+
+// Groups DRIPs by RoadId, skipping those without one
+func groupByRoad(drips []Drip) map[string][]Drip {
+	roads := make(map[string][]Drip)
+
+	for _, drip := range drips {
+		if drip.RoadId == "" {
+			continue
+		}
+		roads[drip.RoadId] = append(roads[drip.RoadId], drip)
+	}
+
+	return roads
+}
+
+// Sorts DRIPs by ascending RoadOffset, those without an offset go last
+func sortByOffset(drips []Drip) {
+	sort.SliceStable(drips, func(i, j int) bool {
+		a, b := drips[i].RoadOffset, drips[j].RoadOffset
+		if a == -1 || b == -1 {
+			return b == -1 && a != -1
+		}
+		return a < b
+	})
+}
+
+func summarizeRoads(drips []Drip) []RoadSummary {
+	roads := groupByRoad(drips)
+	out := make([]RoadSummary, 0, len(roads))
+
+	for roadId, roadDrips := range roads {
+		sortByOffset(roadDrips)
+
+		summary := RoadSummary{
+			RoadId:         roadId,
+			Count:          len(roadDrips),
+			ActiveMessages: make([]RoadMessage, 0),
+		}
+
+		for _, drip := range roadDrips {
+			if !drip.Working {
+				summary.NonWorking++
+			}
+
+			if drip.DisplayState == DisplayActive {
+				summary.ActiveMessages = append(summary.ActiveMessages, RoadMessage{
+					DripId:     drip.Id,
+					RoadSide:   drip.RoadSide,
+					RoadOffset: drip.RoadOffset,
+					TextLines:  drip.TextLines,
+				})
+			}
+		}
+
+		out = append(out, summary)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].RoadId < out[j].RoadId
+	})
+
+	return out
+}
+
+func roadDetail(drips []Drip, roadId string) (RoadDetail, bool) {
+	roadDrips, found := groupByRoad(drips)[roadId]
+	if !found {
+		return RoadDetail{}, false
+	}
+
+	detail := RoadDetail{
+		RoadId: roadId,
+		Sides:  make(map[string][]Drip),
+	}
+
+	for _, drip := range roadDrips {
+		detail.Sides[drip.RoadSide] = append(detail.Sides[drip.RoadSide], drip)
+	}
+
+	for _, sideDrips := range detail.Sides {
+		sortByOffset(sideDrips)
+	}
+
+	return detail, true
+}
+
+// Serves /roads as a summary of all roads, and /roads/{id} as the DRIPs along a single road
+func handleRoads(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serv.Lock()
+		defer serv.Unlock()
+
+		roadId := strings.ToUpper(strings.Trim(strings.TrimPrefix(r.URL.Path, "/roads"), "/"))
+
+		var data any
+		if roadId == "" {
+			data = summarizeRoads(serv.DripsSlice)
+		} else {
+			detail, found := roadDetail(serv.DripsSlice, roadId)
+			if !found {
+				w.WriteHeader(404)
+				return
+			}
+			data = detail
+		}
+
+		str, err := json.Marshal(data)
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(str)
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestRoadSummaries(t *testing.T) {
+	drips := []Drip{
+		{Id: "1", RoadId: "A2", RoadSide: "R", RoadOffset: 48900, Working: true, TextLines: []string{"FILE"}, DisplayState: DisplayActive},
+		{Id: "2", RoadId: "A2", RoadSide: "R", RoadOffset: 40000, Working: false, DisplayState: DisplayOff},
+		{Id: "3", RoadId: "A2", RoadSide: "L", RoadOffset: -1, Working: true, TextLines: []string{"TEST"}, DisplayState: DisplayTest},
+		{Id: "4", RoadId: "A2", RoadSide: "L", RoadOffset: 62200, Working: true, DisplayState: DisplayActive},
+		{Id: "5", RoadId: "N211", RoadSide: "R", RoadOffset: 12700, Working: true},
+		{Id: "6", Working: true},
+	}
+
+	summaries := summarizeRoads(drips)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 roads, not %v", len(summaries))
+	}
+
+	a2 := summaries[0]
+	assert(t, a2.RoadId, "A2")
+	assert(t, a2.Count, 4)
+	assert(t, a2.NonWorking, 1)
+	// A test pattern has text but isn't a message, an image without text is one
+	assert(t, len(a2.ActiveMessages), 2)
+	assert(t, a2.ActiveMessages[0].DripId, "1")
+	assert(t, a2.ActiveMessages[1].DripId, "4")
+
+	detail, found := roadDetail(drips, "A2")
+	if !found {
+		t.Fatal("Expected to find road A2")
+	}
+
+	assert(t, detail.Sides["R"][0].Id, "2")
+	assert(t, detail.Sides["R"][1].Id, "1")
+	assert(t, detail.Sides["L"][0].Id, "4")
+	assert(t, detail.Sides["L"][1].Id, "3")
+
+	if _, found := roadDetail(drips, "A1"); found {
+		t.Error("Expected unknown road not to be found")
+	}
+}
@@ -0,0 +1,950 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        (unknown)
+// source: rpc/drips.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Image struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Content addressed, changes whenever the image does
+	Url    string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Width  int32  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	// Hex encoded SHA-256 of the PNG
+	Hash string `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Hex encoded difference hash, similar images differ in few bits
+	PerceptualHash string `protobuf:"bytes,5,opt,name=perceptual_hash,json=perceptualHash,proto3" json:"perceptual_hash,omitempty"`
+	// Drawn from the text lines, for panels that only send text
+	Rendered bool `protobuf:"varint,6,opt,name=rendered,proto3" json:"rendered,omitempty"`
+}
+
+func (x *Image) Reset() {
+	*x = Image{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Image) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Image) ProtoMessage() {}
+
+func (x *Image) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Image.ProtoReflect.Descriptor instead.
+func (*Image) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Image) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Image) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Image) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Image) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Image) GetPerceptualHash() string {
+	if x != nil {
+		return x.PerceptualHash
+	}
+	return ""
+}
+
+func (x *Image) GetRendered() bool {
+	if x != nil {
+		return x.Rendered
+	}
+	return false
+}
+
+type Route struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Target  string `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Via     string `protobuf:"bytes,2,opt,name=via,proto3" json:"via,omitempty"`
+	Minutes int32  `protobuf:"varint,3,opt,name=minutes,proto3" json:"minutes,omitempty"`
+}
+
+func (x *Route) Reset() {
+	*x = Route{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Route) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Route) ProtoMessage() {}
+
+func (x *Route) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Route.ProtoReflect.Descriptor instead.
+func (*Route) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Route) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Route) GetVia() string {
+	if x != nil {
+		return x.Via
+	}
+	return ""
+}
+
+func (x *Route) GetMinutes() int32 {
+	if x != nil {
+		return x.Minutes
+	}
+	return 0
+}
+
+type Drip struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Absent when the location table holds no usable position
+	Lat              *float64 `protobuf:"fixed64,3,opt,name=lat,proto3,oneof" json:"lat,omitempty"`
+	Lon              *float64 `protobuf:"fixed64,4,opt,name=lon,proto3,oneof" json:"lon,omitempty"`
+	Working          bool     `protobuf:"varint,5,opt,name=working,proto3" json:"working,omitempty"`
+	Organization     string   `protobuf:"bytes,6,opt,name=organization,proto3" json:"organization,omitempty"`
+	OrganizationCode string   `protobuf:"bytes,7,opt,name=organization_code,json=organizationCode,proto3" json:"organization_code,omitempty"`
+	RoadId           string   `protobuf:"bytes,8,opt,name=road_id,json=roadId,proto3" json:"road_id,omitempty"`
+	RoadSide         string   `protobuf:"bytes,9,opt,name=road_side,json=roadSide,proto3" json:"road_side,omitempty"`
+	// Meters along the road, -1 if unknown
+	RoadOffset       int32    `protobuf:"varint,10,opt,name=road_offset,json=roadOffset,proto3" json:"road_offset,omitempty"`
+	Carriageway      string   `protobuf:"bytes,11,opt,name=carriageway,proto3" json:"carriageway,omitempty"`
+	Junction         string   `protobuf:"bytes,12,opt,name=junction,proto3" json:"junction,omitempty"`
+	HectometerLetter string   `protobuf:"bytes,13,opt,name=hectometer_letter,json=hectometerLetter,proto3" json:"hectometer_letter,omitempty"`
+	Text             []string `protobuf:"bytes,14,rep,name=text,proto3" json:"text,omitempty"`
+	Routes           []*Route `protobuf:"bytes,15,rep,name=routes,proto3" json:"routes,omitempty"`
+	// Absent if the DRIP shows no image
+	Image            *Image   `protobuf:"bytes,16,opt,name=image,proto3" json:"image,omitempty"`
+	CoordinateStatus string   `protobuf:"bytes,17,opt,name=coordinate_status,json=coordinateStatus,proto3" json:"coordinate_status,omitempty"`
+	RdX              *float64 `protobuf:"fixed64,18,opt,name=rd_x,json=rdX,proto3,oneof" json:"rd_x,omitempty"`
+	RdY              *float64 `protobuf:"fixed64,19,opt,name=rd_y,json=rdY,proto3,oneof" json:"rd_y,omitempty"`
+}
+
+func (x *Drip) Reset() {
+	*x = Drip{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Drip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Drip) ProtoMessage() {}
+
+func (x *Drip) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Drip.ProtoReflect.Descriptor instead.
+func (*Drip) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Drip) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Drip) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Drip) GetLat() float64 {
+	if x != nil && x.Lat != nil {
+		return *x.Lat
+	}
+	return 0
+}
+
+func (x *Drip) GetLon() float64 {
+	if x != nil && x.Lon != nil {
+		return *x.Lon
+	}
+	return 0
+}
+
+func (x *Drip) GetWorking() bool {
+	if x != nil {
+		return x.Working
+	}
+	return false
+}
+
+func (x *Drip) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *Drip) GetOrganizationCode() string {
+	if x != nil {
+		return x.OrganizationCode
+	}
+	return ""
+}
+
+func (x *Drip) GetRoadId() string {
+	if x != nil {
+		return x.RoadId
+	}
+	return ""
+}
+
+func (x *Drip) GetRoadSide() string {
+	if x != nil {
+		return x.RoadSide
+	}
+	return ""
+}
+
+func (x *Drip) GetRoadOffset() int32 {
+	if x != nil {
+		return x.RoadOffset
+	}
+	return 0
+}
+
+func (x *Drip) GetCarriageway() string {
+	if x != nil {
+		return x.Carriageway
+	}
+	return ""
+}
+
+func (x *Drip) GetJunction() string {
+	if x != nil {
+		return x.Junction
+	}
+	return ""
+}
+
+func (x *Drip) GetHectometerLetter() string {
+	if x != nil {
+		return x.HectometerLetter
+	}
+	return ""
+}
+
+func (x *Drip) GetText() []string {
+	if x != nil {
+		return x.Text
+	}
+	return nil
+}
+
+func (x *Drip) GetRoutes() []*Route {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *Drip) GetImage() *Image {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *Drip) GetCoordinateStatus() string {
+	if x != nil {
+		return x.CoordinateStatus
+	}
+	return ""
+}
+
+func (x *Drip) GetRdX() float64 {
+	if x != nil && x.RdX != nil {
+		return *x.RdX
+	}
+	return 0
+}
+
+func (x *Drip) GetRdY() float64 {
+	if x != nil && x.RdY != nil {
+		return *x.RdY
+	}
+	return 0
+}
+
+type ListDripsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Only list DRIPs on this road, like "A2"
+	RoadId string `protobuf:"bytes,1,opt,name=road_id,json=roadId,proto3" json:"road_id,omitempty"`
+	// Only list DRIPs of this organization, like "PZH"
+	OrganizationCode string `protobuf:"bytes,2,opt,name=organization_code,json=organizationCode,proto3" json:"organization_code,omitempty"`
+	// At most this many DRIPs per page, 100 if 0
+	PageSize  int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListDripsRequest) Reset() {
+	*x = ListDripsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDripsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDripsRequest) ProtoMessage() {}
+
+func (x *ListDripsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDripsRequest.ProtoReflect.Descriptor instead.
+func (*ListDripsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListDripsRequest) GetRoadId() string {
+	if x != nil {
+		return x.RoadId
+	}
+	return ""
+}
+
+func (x *ListDripsRequest) GetOrganizationCode() string {
+	if x != nil {
+		return x.OrganizationCode
+	}
+	return ""
+}
+
+func (x *ListDripsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListDripsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListDripsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Drips []*Drip `protobuf:"bytes,1,rep,name=drips,proto3" json:"drips,omitempty"`
+	// Empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	Total         int32  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *ListDripsResponse) Reset() {
+	*x = ListDripsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDripsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDripsResponse) ProtoMessage() {}
+
+func (x *ListDripsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDripsResponse.ProtoReflect.Descriptor instead.
+func (*ListDripsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListDripsResponse) GetDrips() []*Drip {
+	if x != nil {
+		return x.Drips
+	}
+	return nil
+}
+
+func (x *ListDripsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListDripsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetDripRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetDripRequest) Reset() {
+	*x = GetDripRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDripRequest) ProtoMessage() {}
+
+func (x *GetDripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDripRequest.ProtoReflect.Descriptor instead.
+func (*GetDripRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetDripRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WatchDripsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Only report DRIPs on this road
+	RoadId string `protobuf:"bytes,1,opt,name=road_id,json=roadId,proto3" json:"road_id,omitempty"`
+	// Send every current DRIP as the first event
+	Initial bool `protobuf:"varint,2,opt,name=initial,proto3" json:"initial,omitempty"`
+}
+
+func (x *WatchDripsRequest) Reset() {
+	*x = WatchDripsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchDripsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDripsRequest) ProtoMessage() {}
+
+func (x *WatchDripsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDripsRequest.ProtoReflect.Descriptor instead.
+func (*WatchDripsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchDripsRequest) GetRoadId() string {
+	if x != nil {
+		return x.RoadId
+	}
+	return ""
+}
+
+func (x *WatchDripsRequest) GetInitial() bool {
+	if x != nil {
+		return x.Initial
+	}
+	return false
+}
+
+type DripEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UpdateTimeUnixMs int64    `protobuf:"varint,1,opt,name=update_time_unix_ms,json=updateTimeUnixMs,proto3" json:"update_time_unix_ms,omitempty"`
+	Changed          []*Drip  `protobuf:"bytes,2,rep,name=changed,proto3" json:"changed,omitempty"`
+	Removed          []string `protobuf:"bytes,3,rep,name=removed,proto3" json:"removed,omitempty"`
+}
+
+func (x *DripEvent) Reset() {
+	*x = DripEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_drips_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DripEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DripEvent) ProtoMessage() {}
+
+func (x *DripEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_drips_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DripEvent.ProtoReflect.Descriptor instead.
+func (*DripEvent) Descriptor() ([]byte, []int) {
+	return file_rpc_drips_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DripEvent) GetUpdateTimeUnixMs() int64 {
+	if x != nil {
+		return x.UpdateTimeUnixMs
+	}
+	return 0
+}
+
+func (x *DripEvent) GetChanged() []*Drip {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *DripEvent) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+var File_rpc_drips_proto protoreflect.FileDescriptor
+
+var file_rpc_drips_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x72, 0x70, 0x63, 0x2f, 0x64, 0x72, 0x69, 0x70, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x0d, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76, 0x31,
+	0x22, 0xa0, 0x01, 0x0a, 0x05, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61,
+	0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x27,
+	0x0a, 0x0f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74, 0x75, 0x61, 0x6c, 0x5f, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74,
+	0x75, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6e, 0x64, 0x65,
+	0x72, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x6e, 0x64, 0x65,
+	0x72, 0x65, 0x64, 0x22, 0x4b, 0x0a, 0x05, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x69, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x76, 0x69, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73,
+	0x22, 0xf2, 0x04, 0x0a, 0x04, 0x44, 0x72, 0x69, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x15, 0x0a,
+	0x03, 0x6c, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x61,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x18, 0x0a, 0x07, 0x77,
+	0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x77, 0x6f,
+	0x72, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x22, 0x0a, 0x0c, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x72, 0x67,
+	0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x6f, 0x72, 0x67,
+	0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x61, 0x64, 0x5f, 0x69,
+	0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x61, 0x64, 0x49, 0x64, 0x12,
+	0x1b, 0x0a, 0x09, 0x72, 0x6f, 0x61, 0x64, 0x5f, 0x73, 0x69, 0x64, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x72, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x64, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x72, 0x6f, 0x61, 0x64, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0a, 0x72, 0x6f, 0x61, 0x64, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x20, 0x0a,
+	0x0b, 0x63, 0x61, 0x72, 0x72, 0x69, 0x61, 0x67, 0x65, 0x77, 0x61, 0x79, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x72, 0x72, 0x69, 0x61, 0x67, 0x65, 0x77, 0x61, 0x79, 0x12,
+	0x1a, 0x0a, 0x08, 0x6a, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x6a, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x68,
+	0x65, 0x63, 0x74, 0x6f, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x5f, 0x6c, 0x65, 0x74, 0x74, 0x65, 0x72,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x68, 0x65, 0x63, 0x74, 0x6f, 0x6d, 0x65, 0x74,
+	0x65, 0x72, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x0e, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x2c, 0x0a, 0x06,
+	0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74,
+	0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x05, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x66,
+	0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52,
+	0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x61, 0x74, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x10, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x04, 0x72, 0x64, 0x5f, 0x78, 0x18, 0x12, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x02, 0x52, 0x03, 0x72, 0x64, 0x58, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x04, 0x72,
+	0x64, 0x5f, 0x79, 0x18, 0x13, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x03, 0x72, 0x64, 0x59,
+	0x88, 0x01, 0x01, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6c, 0x61, 0x74, 0x42, 0x06, 0x0a, 0x04, 0x5f,
+	0x6c, 0x6f, 0x6e, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x72, 0x64, 0x5f, 0x78, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x72, 0x64, 0x5f, 0x79, 0x22, 0x94, 0x01, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72,
+	0x69, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f,
+	0x61, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x61,
+	0x64, 0x49, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x64, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x7c, 0x0a, 0x11,
+	0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x69, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x29, 0x0a, 0x05, 0x64, 0x72, 0x69, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x72, 0x69, 0x70, 0x52, 0x05, 0x64, 0x72, 0x69, 0x70, 0x73, 0x12, 0x26, 0x0a, 0x0f,
+	0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x20, 0x0a, 0x0e, 0x47, 0x65,
+	0x74, 0x44, 0x72, 0x69, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x46, 0x0a, 0x11,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x72, 0x69, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x61, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x61, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e,
+	0x69, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x6e, 0x69,
+	0x74, 0x69, 0x61, 0x6c, 0x22, 0x83, 0x01, 0x0a, 0x09, 0x44, 0x72, 0x69, 0x70, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x13, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x10, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d,
+	0x73, 0x12, 0x2d, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x72, 0x69, 0x70, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64,
+	0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x32, 0xe2, 0x01, 0x0a, 0x05, 0x44,
+	0x72, 0x69, 0x70, 0x73, 0x12, 0x4e, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x69, 0x70,
+	0x73, 0x12, 0x1f, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x69, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x20, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x69, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x44, 0x72, 0x69, 0x70, 0x12,
+	0x1d, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x44, 0x72, 0x69, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13,
+	0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x72, 0x69, 0x70, 0x12, 0x4a, 0x0a, 0x0a, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x72, 0x69, 0x70,
+	0x73, 0x12, 0x20, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x72, 0x69, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x72, 0x69, 0x70, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42,
+	0x24, 0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x75,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x6c, 0x2f, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x6d, 0x61,
+	0x70, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_drips_proto_rawDescOnce sync.Once
+	file_rpc_drips_proto_rawDescData = file_rpc_drips_proto_rawDesc
+)
+
+func file_rpc_drips_proto_rawDescGZIP() []byte {
+	file_rpc_drips_proto_rawDescOnce.Do(func() {
+		file_rpc_drips_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_drips_proto_rawDescData)
+	})
+	return file_rpc_drips_proto_rawDescData
+}
+
+var file_rpc_drips_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_rpc_drips_proto_goTypes = []interface{}{
+	(*Image)(nil),             // 0: trafficmap.v1.Image
+	(*Route)(nil),             // 1: trafficmap.v1.Route
+	(*Drip)(nil),              // 2: trafficmap.v1.Drip
+	(*ListDripsRequest)(nil),  // 3: trafficmap.v1.ListDripsRequest
+	(*ListDripsResponse)(nil), // 4: trafficmap.v1.ListDripsResponse
+	(*GetDripRequest)(nil),    // 5: trafficmap.v1.GetDripRequest
+	(*WatchDripsRequest)(nil), // 6: trafficmap.v1.WatchDripsRequest
+	(*DripEvent)(nil),         // 7: trafficmap.v1.DripEvent
+}
+var file_rpc_drips_proto_depIdxs = []int32{
+	1, // 0: trafficmap.v1.Drip.routes:type_name -> trafficmap.v1.Route
+	0, // 1: trafficmap.v1.Drip.image:type_name -> trafficmap.v1.Image
+	2, // 2: trafficmap.v1.ListDripsResponse.drips:type_name -> trafficmap.v1.Drip
+	2, // 3: trafficmap.v1.DripEvent.changed:type_name -> trafficmap.v1.Drip
+	3, // 4: trafficmap.v1.Drips.ListDrips:input_type -> trafficmap.v1.ListDripsRequest
+	5, // 5: trafficmap.v1.Drips.GetDrip:input_type -> trafficmap.v1.GetDripRequest
+	6, // 6: trafficmap.v1.Drips.WatchDrips:input_type -> trafficmap.v1.WatchDripsRequest
+	4, // 7: trafficmap.v1.Drips.ListDrips:output_type -> trafficmap.v1.ListDripsResponse
+	2, // 8: trafficmap.v1.Drips.GetDrip:output_type -> trafficmap.v1.Drip
+	7, // 9: trafficmap.v1.Drips.WatchDrips:output_type -> trafficmap.v1.DripEvent
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_rpc_drips_proto_init() }
+func file_rpc_drips_proto_init() {
+	if File_rpc_drips_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_drips_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Image); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Route); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Drip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDripsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDripsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDripRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchDripsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_drips_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DripEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_drips_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_drips_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rpc_drips_proto_goTypes,
+		DependencyIndexes: file_rpc_drips_proto_depIdxs,
+		MessageInfos:      file_rpc_drips_proto_msgTypes,
+	}.Build()
+	File_rpc_drips_proto = out.File
+	file_rpc_drips_proto_rawDesc = nil
+	file_rpc_drips_proto_goTypes = nil
+	file_rpc_drips_proto_depIdxs = nil
+}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: rpc/drips.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Drips_ListDrips_FullMethodName  = "/trafficmap.v1.Drips/ListDrips"
+	Drips_GetDrip_FullMethodName    = "/trafficmap.v1.Drips/GetDrip"
+	Drips_WatchDrips_FullMethodName = "/trafficmap.v1.Drips/WatchDrips"
+)
+
+// DripsClient is the client API for Drips service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DRIP data as served over HTTP by /api/v1/, updated every five minutes
+type DripsClient interface {
+	ListDrips(ctx context.Context, in *ListDripsRequest, opts ...grpc.CallOption) (*ListDripsResponse, error)
+	GetDrip(ctx context.Context, in *GetDripRequest, opts ...grpc.CallOption) (*Drip, error)
+	// Streams the DRIPs that changed after every update cycle
+	// A stream that falls behind ends with UNAVAILABLE, reconnect with initial set to catch up
+	WatchDrips(ctx context.Context, in *WatchDripsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DripEvent], error)
+}
+
+type dripsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDripsClient(cc grpc.ClientConnInterface) DripsClient {
+	return &dripsClient{cc}
+}
+
+func (c *dripsClient) ListDrips(ctx context.Context, in *ListDripsRequest, opts ...grpc.CallOption) (*ListDripsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDripsResponse)
+	err := c.cc.Invoke(ctx, Drips_ListDrips_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dripsClient) GetDrip(ctx context.Context, in *GetDripRequest, opts ...grpc.CallOption) (*Drip, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Drip)
+	err := c.cc.Invoke(ctx, Drips_GetDrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dripsClient) WatchDrips(ctx context.Context, in *WatchDripsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DripEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Drips_ServiceDesc.Streams[0], Drips_WatchDrips_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchDripsRequest, DripEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Drips_WatchDripsClient = grpc.ServerStreamingClient[DripEvent]
+
+// DripsServer is the server API for Drips service.
+// All implementations must embed UnimplementedDripsServer
+// for forward compatibility.
+//
+// DRIP data as served over HTTP by /api/v1/, updated every five minutes
+type DripsServer interface {
+	ListDrips(context.Context, *ListDripsRequest) (*ListDripsResponse, error)
+	GetDrip(context.Context, *GetDripRequest) (*Drip, error)
+	// Streams the DRIPs that changed after every update cycle
+	// A stream that falls behind ends with UNAVAILABLE, reconnect with initial set to catch up
+	WatchDrips(*WatchDripsRequest, grpc.ServerStreamingServer[DripEvent]) error
+	mustEmbedUnimplementedDripsServer()
+}
+
+// UnimplementedDripsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDripsServer struct{}
+
+func (UnimplementedDripsServer) ListDrips(context.Context, *ListDripsRequest) (*ListDripsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDrips not implemented")
+}
+func (UnimplementedDripsServer) GetDrip(context.Context, *GetDripRequest) (*Drip, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDrip not implemented")
+}
+func (UnimplementedDripsServer) WatchDrips(*WatchDripsRequest, grpc.ServerStreamingServer[DripEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDrips not implemented")
+}
+func (UnimplementedDripsServer) mustEmbedUnimplementedDripsServer() {}
+func (UnimplementedDripsServer) testEmbeddedByValue()               {}
+
+// UnsafeDripsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DripsServer will
+// result in compilation errors.
+type UnsafeDripsServer interface {
+	mustEmbedUnimplementedDripsServer()
+}
+
+func RegisterDripsServer(s grpc.ServiceRegistrar, srv DripsServer) {
+	// If the following call pancis, it indicates UnimplementedDripsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Drips_ServiceDesc, srv)
+}
+
+func _Drips_ListDrips_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDripsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DripsServer).ListDrips(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Drips_ListDrips_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DripsServer).ListDrips(ctx, req.(*ListDripsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Drips_GetDrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DripsServer).GetDrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Drips_GetDrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DripsServer).GetDrip(ctx, req.(*GetDripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Drips_WatchDrips_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDripsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DripsServer).WatchDrips(m, &grpc.GenericServerStream[WatchDripsRequest, DripEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Drips_WatchDripsServer = grpc.ServerStreamingServer[DripEvent]
+
+// Drips_ServiceDesc is the grpc.ServiceDesc for Drips service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Drips_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "trafficmap.v1.Drips",
+	HandlerType: (*DripsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDrips",
+			Handler:    _Drips_ListDrips_Handler,
+		},
+		{
+			MethodName: "GetDrip",
+			Handler:    _Drips_GetDrip_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDrips",
+			Handler:       _Drips_WatchDrips_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rpc/drips.proto",
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+)
+
+func testDrip() *Drip {
+	lat, lon := 52.1, 4.2
+
+	return &Drip{
+		Id:         "ID_1",
+		Name:       "Hoefweg",
+		Lat:        &lat,
+		Lon:        &lon,
+		Working:    true,
+		RoadId:     "A2",
+		RoadOffset: -1,
+		Text:       []string{"FILE", "A2 12 MIN"},
+		Routes:     []*Route{{Target: "Utrecht", Via: "A2", Minutes: 12}},
+		Image: &Image{
+			Url:      "/images/abc.png",
+			Width:    40,
+			Height:   40,
+			Hash:     "abc",
+			Rendered: true,
+		},
+	}
+}
+
+func TestDripRoundTrip(t *testing.T) {
+	data, err := proto.Marshal(testDrip())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &Drip{}
+	if err := proto.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(got, testDrip()) {
+		t.Errorf("Expected %v, got %v", testDrip(), got)
+	}
+
+	// Optional coordinates stay absent instead of becoming 0
+	if err := proto.Unmarshal(nil, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Lat != nil || got.RdX != nil || got.Image != nil {
+		t.Errorf("Expected absent fields to stay nil, got %v", got)
+	}
+}
+
+func TestDripFieldNumbers(t *testing.T) {
+	// Field 1 as a length-delimited string, then field 5 as a varint
+	data, err := proto.Marshal(&Drip{Id: "x", Working: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []byte{0x0a, 0x01, 'x', 0x28, 0x01}; !bytes.Equal(data, want) {
+		t.Errorf("Expected %x, got %x", want, data)
+	}
+}
+
+func TestGrpcCodecRoundTrip(t *testing.T) {
+	codec := encoding.GetCodec("proto")
+	if codec == nil {
+		t.Fatal("Expected gRPC's proto codec to be registered")
+	}
+
+	event := &DripEvent{UpdateTimeUnixMs: 1700000000000, Changed: []*Drip{testDrip()}, Removed: []string{"ID_3"}}
+	data, err := codec.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &DripEvent{}
+	if err := codec.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(got, event) {
+		t.Errorf("Expected %v, got %v", event, got)
+	}
+}
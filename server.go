@@ -1,11 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"image/png"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -57,23 +60,118 @@ func handleDataRead(serv *DripServ) http.HandlerFunc {
 		w.Write([]byte(str))
 	})
 }
+
+// Path of an image by content, so it can be cached forever
+func contentImagePath(hash, format string) string {
+	return "/img/" + hash + "." + format
+}
+
+// Redirects /images/{id}.png to the image the DRIP currently shows, keeping the query
 func handleImages(serv *DripServ) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pathChunks := strings.Split(r.URL.Path, "/")
+		id, format, _ := strings.Cut(pathChunks[len(pathChunks)-1], ".")
+
 		serv.Lock()
-		defer serv.Unlock()
+		drip, found := serv.dripsMap[id]
+		serv.Unlock()
+
+		if _, known := imageContentTypes[format]; !known || !found || !drip.hasMarkerImage() {
+			w.WriteHeader(404)
+			return
+		}
+
+		target := contentImagePath(drip.ImageHash, format)
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+
+		w.Header().Set("Cache-Control", "no-cache")
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+}
+
+// Serves /img/{sha256}.png, optionally scaled with ?w= or converted by using .jpg or .webp
+// The width is rounded up to one of variantWidths, X-Image-Width holds the width served
+func handleContentImages(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hash, format, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/img/"), ".")
+
+		contentType, found := imageContentTypes[format]
+		if !found {
+			w.WriteHeader(404)
+			return
+		}
+
+		width, err := parseVariantWidth(r.URL.Query().Get("w"))
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		serv.Lock()
+		original, found := serv.imagesByHash[hash]
+		serv.Unlock()
+
+		if !found {
+			w.WriteHeader(404)
+			return
+		}
 
+		img := original
+		if format != "png" || width != 0 {
+			img, err = serv.variants.get(variantKey{hash, format, width}, func() ([]byte, error) {
+				return renderVariant(original, format, width)
+			})
+			if err != nil {
+				fmt.Println(err.Error())
+				w.WriteHeader(500)
+				return
+			}
+		}
+
+		// Without ?w= the original width is served
+		if width == 0 {
+			config, err := png.DecodeConfig(bytes.NewReader(original))
+			if err != nil {
+				fmt.Println(err.Error())
+				w.WriteHeader(500)
+				return
+			}
+			width = config.Width
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Image-Width", strconv.Itoa(width))
+		w.Write(img)
+	})
+}
+
+func handleTravelTimes(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		pathChunks := strings.Split(r.URL.Path, "/")
-		id := strings.TrimSuffix(pathChunks[len(pathChunks)-1], ".png")
+		id := pathChunks[len(pathChunks)-1]
 
-		drip, found := serv.dripsMap[id]
-		if !found || !drip.hasImage() {
+		serv.Lock()
+		_, found := serv.dripsMap[id]
+		serv.Unlock()
+
+		if !found {
 			w.WriteHeader(404)
 			return
 		}
 
-		w.Header().Set("Content-Type", "image/png")
-		w.Write(drip.image)
+		str, err := json.Marshal(serv.travelTimes.Get(id))
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
 
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(str)
 	})
 }
 
@@ -86,7 +184,26 @@ func createMux(serv *DripServ) http.Handler {
 	mux.Handle("/style.css", handleFileRead("style.css", "text/css"))
 	mux.Handle("/favicon.ico", handleFileRead("favicon.ico", "image/png"))
 	mux.Handle("/images/", handleImages(serv))
+	mux.Handle("/img/", handleContentImages(serv))
+	mux.Handle("/sprites.png", handleSprites(serv, "image/png", func(s *spriteSheet) []byte { return s.png }))
+	mux.Handle("/sprites.json", handleSprites(serv, "application/json", func(s *spriteSheet) []byte { return s.index }))
 	mux.Handle("/data.json", handleDataRead(serv))
+	mux.Handle("/traveltimes/", handleTravelTimes(serv))
+	mux.Handle("/drips.csv", handleDripsExport(serv))
+	mux.Handle("/drips.parquet", handleDripsExport(serv))
+	mux.Handle("/drips.kml", handleKml(serv))
+	mux.Handle("/drips.geojson", handleGeoJson(serv))
+	mux.Handle("/traveltimes.csv", handleTravelTimesExport(serv))
+	mux.Handle("/traveltimes.parquet", handleTravelTimesExport(serv))
+	mux.Handle("/organizations", handleOrganizations(serv))
+	mux.Handle("/roads", handleRoads(serv))
+	mux.Handle("/roads/", handleRoads(serv))
+	mux.Handle("/corridor", handleCorridor(serv))
+	mux.Handle("/tiles/", handleTiles(serv))
+	mux.Handle("/clusters", handleClusters(serv))
+	mux.Handle("/timelines/", handleTimelines(serv))
+	registerApi(mux, serv)
+	mux.Handle("/graphql", handleGraphQL(serv))
 
 	return mux
 }
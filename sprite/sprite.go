@@ -0,0 +1,82 @@
+// Package sprite packs many small images into a single atlas image
+package sprite
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// Widest atlas produced, images wider than this get a row of their own
+const MaxWidth = 2048
+
+// Tallest atlas produced, images that don't fit below it are left out of the atlas
+const MaxHeight = 2048
+
+// Pixels between images, so scaled drawing doesn't bleed into neighbours
+const Padding = 1
+
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type Atlas struct {
+	Image *image.RGBA
+	Rects map[string]Rect
+}
+
+// Packs images in shelves, tallest first, so rows waste little height
+// The same set of images always produces the same atlas
+// Only images that fit within MaxHeight get a rect, callers should fall back for the rest
+func Pack(images map[string]image.Image) Atlas {
+	ids := make([]string, 0, len(images))
+	for id := range images {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := images[ids[i]].Bounds(), images[ids[j]].Bounds()
+		if a.Dy() != b.Dy() {
+			return a.Dy() > b.Dy()
+		}
+		return ids[i] < ids[j]
+	})
+
+	rects := make(map[string]Rect, len(ids))
+	x, y, shelfHeight, width := 0, 0, 0, 0
+
+	for _, id := range ids {
+		bounds := images[id].Bounds()
+
+		nextX, nextY, nextShelfHeight := x, y, shelfHeight
+		if nextX > 0 && nextX+bounds.Dx() > MaxWidth {
+			nextX, nextY = 0, nextY+nextShelfHeight+Padding
+			nextShelfHeight = 0
+		}
+
+		if nextY+bounds.Dy() > MaxHeight {
+			continue
+		}
+		x, y, shelfHeight = nextX, nextY, nextShelfHeight
+
+		rects[id] = Rect{X: x, Y: y, Width: bounds.Dx(), Height: bounds.Dy()}
+
+		x += bounds.Dx() + Padding
+		if bounds.Dy() > shelfHeight {
+			shelfHeight = bounds.Dy()
+		}
+		if x-Padding > width {
+			width = x - Padding
+		}
+	}
+
+	atlas := image.NewRGBA(image.Rect(0, 0, width, y+shelfHeight))
+	for id, rect := range rects {
+		img := images[id]
+		draw.Draw(atlas, image.Rect(rect.X, rect.Y, rect.X+rect.Width, rect.Y+rect.Height), img, img.Bounds().Min, draw.Src)
+	}
+
+	return Atlas{Image: atlas, Rects: rects}
+}
@@ -0,0 +1,76 @@
+package sprite
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func overlaps(a, b Rect) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height
+}
+
+func TestPack(t *testing.T) {
+	images := make(map[string]image.Image)
+	for i := 0; i < 40; i++ {
+		images[fmt.Sprintf("ID_%v", i)] = solid(100+i, 40+i%3*20, color.RGBA{uint8(i), 0, 0, 255})
+	}
+
+	atlas := Pack(images)
+
+	if atlas.Image.Bounds().Dx() > MaxWidth {
+		t.Errorf("Atlas is %v wide, more than %v", atlas.Image.Bounds().Dx(), MaxWidth)
+	}
+
+	for id, rect := range atlas.Rects {
+		if !image.Rect(rect.X, rect.Y, rect.X+rect.Width, rect.Y+rect.Height).In(atlas.Image.Bounds()) {
+			t.Errorf("%v lies outside the atlas", id)
+		}
+
+		for other, otherRect := range atlas.Rects {
+			if id != other && overlaps(rect, otherRect) {
+				t.Errorf("%v overlaps %v", id, other)
+			}
+		}
+
+		if got, want := atlas.Image.At(rect.X, rect.Y), images[id].At(0, 0); color.RGBAModel.Convert(got) != color.RGBAModel.Convert(want) {
+			t.Errorf("%v: expected %v in the atlas, got %v", id, want, got)
+		}
+	}
+}
+
+func TestPackEmpty(t *testing.T) {
+	atlas := Pack(map[string]image.Image{})
+	if len(atlas.Rects) != 0 || !atlas.Image.Bounds().Empty() {
+		t.Errorf("Expected an empty atlas")
+	}
+}
+
+func TestPackMaxHeight(t *testing.T) {
+	images := make(map[string]image.Image)
+	for i := 0; i < 100; i++ {
+		images[fmt.Sprintf("ID_%02d", i)] = solid(1000, 100, color.RGBA{uint8(i), 0, 0, 255})
+	}
+
+	atlas := Pack(images)
+
+	if atlas.Image.Bounds().Dy() > MaxHeight {
+		t.Errorf("Atlas is %v high, more than %v", atlas.Image.Bounds().Dy(), MaxHeight)
+	}
+
+	// Two images per shelf, and as many shelves as fit
+	if want := 2 * ((MaxHeight + Padding) / (100 + Padding)); len(atlas.Rects) != want {
+		t.Errorf("Expected %v images in the atlas, got %v", want, len(atlas.Rects))
+	}
+}
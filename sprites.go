@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hunternl/trafficmap/sprite"
+)
+
+// Tells clients where each DRIP image is found in /sprites.png
+type SpriteIndex struct {
+	DateUpdated time.Time `json:"dateUpdated"`
+	// Matches the ETag of /sprites.png, so clients can tell the two belong together
+	Version string `json:"version"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	// One cell per distinct image, keyed by image hash
+	Cells map[string]sprite.Rect `json:"cells"`
+	// Cell of every DRIP in the atlas, DRIPs left out should load their own image
+	Sprites map[string]string `json:"sprites"`
+}
+
+// Encoded atlas of the current DRIP images, rebuilt after every update
+type spriteSheet struct {
+	png   []byte
+	index []byte
+	etag  string
+}
+
+func buildSprites(drips []Drip, updated time.Time) (*spriteSheet, error) {
+	// Many DRIPs show the same image, which only needs to be in the atlas once
+	images := make(map[string]image.Image)
+	for _, drip := range drips {
+		if _, found := images[drip.ImageHash]; found || !drip.hasImage() {
+			continue
+		}
+
+		img, err := png.Decode(bytes.NewReader(drip.image))
+		if err != nil {
+			continue // Already validated in ParseDripsXML
+		}
+		images[drip.ImageHash] = img
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images to build sprites from")
+	}
+
+	atlas := sprite.Pack(images)
+
+	cells := make(map[string]string)
+	for _, drip := range drips {
+		if _, found := atlas.Rects[drip.ImageHash]; found && drip.hasImage() {
+			cells[drip.Id] = drip.ImageHash
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(buf, atlas.Image); err != nil {
+		return nil, fmt.Errorf("error encoding sprites: %w", err)
+	}
+
+	hash := sha256.Sum256(buf.Bytes())
+	version := hex.EncodeToString(hash[:8])
+
+	index, err := json.Marshal(SpriteIndex{
+		DateUpdated: updated,
+		Version:     version,
+		Width:       atlas.Image.Bounds().Dx(),
+		Height:      atlas.Image.Bounds().Dy(),
+		Cells:       atlas.Rects,
+		Sprites:     cells,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spriteSheet{png: buf.Bytes(), index: index, etag: `"` + version + `"`}, nil
+}
+
+// Serves either part of the sprite sheet, answering 304 when the client already has it
+func handleSprites(serv *DripServ, contentType string, body func(*spriteSheet) []byte) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serv.Lock()
+		sheet := serv.sprites
+		serv.Unlock()
+
+		if sheet == nil {
+			w.WriteHeader(404)
+			return
+		}
+
+		w.Header().Set("ETag", sheet.etag)
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(UpdateInterval.Seconds())))
+
+		if r.Header.Get("If-None-Match") == sheet.etag {
+			w.WriteHeader(304)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body(sheet))
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSprites(t *testing.T) {
+	serv := newTestServ(t)
+	mux := createMux(serv)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/sprites.json", nil))
+	assert(t, recorder.Code, 404)
+
+	sprites, err := buildSprites(serv.DripsSlice, serv.LastUpdate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serv.sprites = sprites
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/sprites.json", nil))
+	assert(t, recorder.Code, 200)
+
+	var index SpriteIndex
+	if err := json.Unmarshal(recorder.Body.Bytes(), &index); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rendered images load on their own, so only ID_2 is in the atlas
+	assert(t, len(index.Sprites), 1)
+	assert(t, len(index.Cells), 1)
+	assert(t, index.Cells[index.Sprites["ID_2"]].Width, 40)
+	assert(t, recorder.Header().Get("ETag"), `"`+index.Version+`"`)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/sprites.png", nil))
+	assert(t, recorder.Code, 200)
+
+	atlas, err := png.Decode(bytes.NewReader(recorder.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, atlas.Bounds().Dx(), index.Width)
+
+	request := httptest.NewRequest("GET", "/sprites.png", nil)
+	request.Header.Set("If-None-Match", recorder.Header().Get("ETag"))
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+	assert(t, recorder.Code, 304)
+}
+
+func TestSpritesShareCells(t *testing.T) {
+	serv := newTestServ(t)
+
+	duplicate := serv.dripsMap["ID_2"]
+	duplicate.Id = "ID_4"
+	drips := append(serv.DripsSlice, duplicate)
+
+	sheet, err := buildSprites(drips, serv.LastUpdate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var index SpriteIndex
+	if err := json.Unmarshal(sheet.index, &index); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, len(index.Sprites), 2)
+	assert(t, len(index.Cells), 1)
+	assert(t, index.Sprites["ID_4"], index.Sprites["ID_2"])
+}
@@ -11,7 +11,14 @@ function onReady(f) {
 }
 
 async function getData() {
-    return fetch("./data.json").then(r => r.json())
+    return fetch("./api/v1/drips").then(r => r.json())
+}
+
+// Resolves to null when there's no sprite sheet, markers then load their own image
+async function getSprites() {
+    return fetch("./sprites.json")
+        .then(r => r.ok ? r.json() : null)
+        .catch(() => null)
 }
 
 function setSidebarVisibility(bool) {
@@ -23,8 +30,9 @@ function setSidebarVisibility(bool) {
     
 }
 
-function imageForDripId(id) {
-    return "./images/" + id + ".png"
+// Content addressed, so the browser can cache it for good
+function imageForDrip(drip) {
+    return "." + drip.image.url
 }
 
 
@@ -74,7 +82,7 @@ function renderDripToSidebar(sidebarElement, drip) {
     // writeToElem("drip_text",drip.text ? drip.text.join() : "");
     
 
-    if(drip.roadId != "" && drip.roadOffset >= 0) {
+    if(drip.roadId != "" && drip.roadOffset !== null) {
         hectoElem.style.display = "block"
         writeToElem("hecto_road",drip.roadId)
         writeToElem("hecto_bottom",formatOffset(drip.roadOffset))
@@ -88,7 +96,7 @@ function renderDripToSidebar(sidebarElement, drip) {
 
 
     const img = sidebarElement.querySelector("img")
-    img.src = imageForDripId(drip.id)
+    img.src = drip.image ? imageForDrip(drip) : ""
 }
 
 function onMarkerClick(event,data) {
@@ -185,13 +193,24 @@ onReady(() => {
     document.getElementById("close-button")?.addEventListener("click", () => setSidebarVisibility(false))
 })
 
-function createIcon(drip) {
-    if(drip.imageWidth && drip.imageHeight) {
-        const imgX = parseInt(drip.imageWidth)
-        const imgY = parseInt(drip.imageHeight)
+function createIcon(drip, sprites) {
+    if(drip.image) {
+        const imgX = drip.image.width
+        const imgY = drip.image.height
+        const sprite = sprites?.cells[sprites.sprites[drip.id]]
+
+        if(sprite) {
+            return L.icon({
+                iconUrl: "./sprites.png?v=" + sprites.version,
+                spriteRect: [sprite.x, sprite.y, sprite.width, sprite.height],
+                iconSize: [imgX, imgY],
+                iconSizeOrig: [imgX, imgY],
+                iconAnchor: [imgX / 2, imgY / 2],
+            })
+        }
 
         return L.icon({
-            iconUrl: imageForDripId(drip.id),
+            iconUrl: imageForDrip(drip),
             iconSize: [imgX, imgY],
             iconSizeOrig: [imgX, imgY],
             iconAnchor: [imgX / 2, imgY / 2],
@@ -234,25 +253,22 @@ function setupMap() {
     map.attributionControl.addAttribution('Data: <a href="http://opendata.ndw.nu/">opendata.ndw.nu/</a>')
 
 
-    getData().then(d => {
+    Promise.all([getData(), getSprites()]).then(([d, sprites]) => {
 
         d.drips.forEach(drip => {
             dripDb.set(drip.id, drip)
         })
 
         d.drips.forEach(drip => {
-            const lat = parseFloat(drip.lat, 10)
-            const lon = parseFloat(drip.lon, 10)
-
-            if (Number.isNaN(lat) || Number.isNaN(lon)) {
+            if (drip.lat === null || drip.lon === null) {
                 return
             }
 
-            
-            if(drip.imageHeight == 0 || drip.imageWidth == 0 ) {
+            // Blank, test and switched off displays would only clutter the map
+            if(!drip.image || drip.displayState !== "active") {
                 return
             }
-            const marker = L.marker([lat, lon], { icon:createIcon(drip) })
+            const marker = L.marker([drip.lat, drip.lon], { icon:createIcon(drip, sprites) })
             
             marker.dripId = drip.id
 
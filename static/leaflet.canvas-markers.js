@@ -282,6 +282,21 @@ function layerFactory(L) {
             this._context.globalAlpha = (typeof this.options.opacity == "number" ? this.options.opacity : 1)
             // EDIT EDIT
 
+            // START EDIT TO SUPPORT SPRITES
+            if (options.spriteRect) {
+                var rect = options.spriteRect;
+                this._context.drawImage(
+                    marker.canvas_img,
+                    rect[0], rect[1], rect[2], rect[3],
+                    pointPos.x - options.iconAnchor[0],
+                    pointPos.y - options.iconAnchor[1],
+                    options.iconSize[0],
+                    options.iconSize[1]
+                );
+                return;
+            }
+            // END EDIT
+
             this._context.drawImage(
                 marker.canvas_img,
                 pointPos.x - options.iconAnchor[0],
@@ -0,0 +1,92 @@
+// Package textpanel draws the text lines of a DRIP the way the panel shows them
+package textpanel
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Amber of the LEDs most text panels use
+var Amber = color.RGBA{0xff, 0xb0, 0x00, 0xff}
+
+// Every font pixel becomes a Scale x Scale block, like an LED
+const Scale = 2
+
+// Font pixels around the text and between lines
+const (
+	Margin      = 4
+	LineSpacing = 3
+)
+
+var face = basicfont.Face7x13
+
+// Characters the font can't draw are shown as '?', like most panel controllers do
+func printable(line string) string {
+	return strings.Map(func(r rune) rune {
+		if _, ok := face.GlyphAdvance(r); !ok {
+			return '?'
+		}
+		return r
+	}, line)
+}
+
+// Draws lines centered under each other in amber on black
+// Empty lines at the start and end are left out, empty lines in between keep their space
+func Render(lines []string) *image.RGBA {
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	lineHeight := face.Height + LineSpacing
+	columns := 0
+	shown := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		line = printable(strings.TrimSpace(line))
+		if n := len([]rune(line)); n > columns {
+			columns = n
+		}
+		shown = append(shown, line)
+	}
+
+	width := columns*face.Advance + 2*Margin
+	height := len(shown)*lineHeight - LineSpacing + 2*Margin
+	if len(shown) == 0 {
+		height = 2 * Margin
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(small, small.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	drawer := font.Drawer{Dst: small, Src: image.NewUniform(Amber), Face: face}
+	for i, line := range shown {
+		x := (width - len([]rune(line))*face.Advance) / 2
+		y := Margin + i*lineHeight + face.Ascent
+		drawer.Dot = fixed.P(x, y)
+		drawer.DrawString(line)
+	}
+
+	// Basicfont has no anti-aliasing, so copying blocks keeps the LED look
+	img := image.NewRGBA(image.Rect(0, 0, width*Scale, height*Scale))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := small.RGBAAt(x, y)
+			for dy := 0; dy < Scale; dy++ {
+				for dx := 0; dx < Scale; dx++ {
+					img.SetRGBA(x*Scale+dx, y*Scale+dy, c)
+				}
+			}
+		}
+	}
+
+	return img
+}
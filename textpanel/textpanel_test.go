@@ -0,0 +1,54 @@
+package textpanel
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestRenderSize(t *testing.T) {
+	img := Render([]string{"", "A2 UTRECHT", "", "12 MIN", ""})
+
+	// Longest line of 10 characters, 3 lines with the empty one in between kept
+	wantWidth := (10*face.Advance + 2*Margin) * Scale
+	wantHeight := (3*(face.Height+LineSpacing) - LineSpacing + 2*Margin) * Scale
+
+	if img.Bounds().Dx() != wantWidth || img.Bounds().Dy() != wantHeight {
+		t.Errorf("got %v, want %vx%v", img.Bounds(), wantWidth, wantHeight)
+	}
+}
+
+func TestRenderColors(t *testing.T) {
+	img := Render([]string{"FILE"})
+
+	amber, black := 0, 0
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			switch img.RGBAAt(x, y) {
+			case Amber:
+				amber++
+			case color.RGBA{0, 0, 0, 0xff}:
+				black++
+			default:
+				t.Fatalf("unexpected color %v at %v,%v", img.RGBAAt(x, y), x, y)
+			}
+		}
+	}
+
+	if amber == 0 || amber > black {
+		t.Errorf("expected some amber text on a mostly black panel, got %v amber and %v black pixels", amber, black)
+	}
+
+	// Margins stay dark
+	if img.RGBAAt(0, 0) != (color.RGBA{0, 0, 0, 0xff}) {
+		t.Errorf("expected a black corner, got %v", img.RGBAAt(0, 0))
+	}
+}
+
+func TestRenderKeepsLines(t *testing.T) {
+	lines := []string{" FILE ", "☃"}
+	Render(lines)
+
+	if lines[0] != " FILE " || lines[1] != "☃" {
+		t.Errorf("lines were modified: %q", lines)
+	}
+}
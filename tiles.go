@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hunternl/trafficmap/cluster"
+	"github.com/hunternl/trafficmap/coordinate"
+	"github.com/hunternl/trafficmap/mvt"
+)
+
+// Up to this zoom level DRIPs that would overlap are merged into clusters
+const clusterMaxZoom = 11
+
+// In pixels, DRIPs closer together than about twice this are clustered
+const clusterRadius = 40
+
+const maxTileZoom = 22
+
+// In pixels, DRIPs this close outside a tile are put on it as well
+// so a marker on the edge of a tile isn't cut off when the neighbouring tile draws it
+const tileBuffer = 32
+
+// Parses a /tiles/{z}/{x}/{y}.mvt path
+func parseTilePath(path string) (z, x, y int, ok bool) {
+	chunks := strings.Split(strings.TrimPrefix(path, "/tiles/"), "/")
+	if len(chunks) != 3 || !strings.HasSuffix(chunks[2], ".mvt") {
+		return 0, 0, 0, false
+	}
+
+	nums := make([]int, 3)
+	for i, chunk := range []string{chunks[0], chunks[1], strings.TrimSuffix(chunks[2], ".mvt")} {
+		num, err := strconv.Atoi(chunk)
+		if err != nil || num < 0 {
+			return 0, 0, 0, false
+		}
+		nums[i] = num
+	}
+
+	z, x, y = nums[0], nums[1], nums[2]
+	if z > maxTileZoom || x >= 1<<z || y >= 1<<z {
+		return 0, 0, 0, false
+	}
+
+	return z, x, y, true
+}
+
+func clusterPoints(drips []Drip) []cluster.Point {
+	points := make([]cluster.Point, 0, len(drips))
+	for _, drip := range drips {
+		if drip.CoordinateStatus == coordinate.StatusInvalid {
+			continue
+		}
+		points = append(points, cluster.Point{Id: drip.Id, Lat: drip.Latitude, Lon: drip.Longitude})
+	}
+	return points
+}
+
+func dripTileProperties(d Drip) map[string]any {
+	properties := map[string]any{
+		"id":               d.Id,
+		"name":             d.Name,
+		"working":          d.Working,
+		"displayState":     string(d.DisplayState),
+		"roadId":           d.RoadId,
+		"organizationCode": d.OrganizationCode,
+		"hasImage":         d.hasImage(),
+	}
+
+	if d.hasMarkerImage() {
+		properties["image"] = contentImagePath(d.ImageHash, "png")
+	}
+
+	return properties
+}
+
+// Places a position on tile x, y at zoom z, reporting whether it falls within the tile or its buffer
+// Positions are floored, so those just left of or above the tile don't end up on its edge
+func tilePosition(lat, lon float64, z, x, y int) (int, int, bool) {
+	px, py := cluster.Project(lat, lon, z)
+	tileX := int(math.Floor((px - float64(x*cluster.TileSize)) * mvt.Extent / cluster.TileSize))
+	tileY := int(math.Floor((py - float64(y*cluster.TileSize)) * mvt.Extent / cluster.TileSize))
+
+	buffer := tileBuffer * mvt.Extent / cluster.TileSize
+	onTile := tileX >= -buffer && tileX < mvt.Extent+buffer && tileY >= -buffer && tileY < mvt.Extent+buffer
+
+	return tileX, tileY, onTile
+}
+
+// Builds the "drips" layer of a tile, with clusters instead of single DRIPs at low zoom levels
+func dripTileLayer(drips []Drip, z, x, y int) mvt.Layer {
+	layer := mvt.Layer{Name: "drips", Features: make([]mvt.Feature, 0)}
+
+	addFeature := func(lat, lon float64, properties map[string]any) {
+		if tileX, tileY, onTile := tilePosition(lat, lon, z, x, y); onTile {
+			layer.Features = append(layer.Features, mvt.Feature{X: tileX, Y: tileY, Properties: properties})
+		}
+	}
+
+	if z > clusterMaxZoom {
+		for _, drip := range drips {
+			if drip.CoordinateStatus != coordinate.StatusInvalid {
+				addFeature(drip.Latitude, drip.Longitude, dripTileProperties(drip))
+			}
+		}
+		return layer
+	}
+
+	byId := make(map[string]Drip, len(drips))
+	for _, drip := range drips {
+		byId[drip.Id] = drip
+	}
+
+	for _, c := range summarizeClusters(drips, z) {
+		if c.DripId != "" {
+			addFeature(c.Lat, c.Lon, dripTileProperties(byId[c.DripId]))
+			continue
+		}
+
+		addFeature(c.Lat, c.Lon, map[string]any{
+			"cluster":    true,
+			"count":      c.Count,
+			"working":    c.Working,
+			"nonWorking": c.NonWorking,
+			"withImage":  c.WithImage,
+		})
+	}
+
+	return layer
+}
+
+func handleTiles(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		z, x, y, ok := parseTilePath(r.URL.Path)
+		if !ok {
+			w.WriteHeader(404)
+			return
+		}
+
+		serv.Lock()
+		layer := dripTileLayer(serv.DripsSlice, z, x, y)
+		serv.Unlock()
+
+		w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(UpdateInterval.Seconds())))
+		w.Write(mvt.Encode(layer))
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hunternl/trafficmap/mvt"
+)
+
+func TestParseTilePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		z, x, y int
+		ok      bool
+	}{
+		{"/tiles/0/0/0.mvt", 0, 0, 0, true},
+		{"/tiles/7/65/42.mvt", 7, 65, 42, true},
+		{"/tiles/7/128/42.mvt", 0, 0, 0, false},
+		{"/tiles/7/65/42.png", 0, 0, 0, false},
+		{"/tiles/7/-1/42.mvt", 0, 0, 0, false},
+		{"/tiles/7/65.mvt", 0, 0, 0, false},
+		{"/tiles/30/0/0.mvt", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		z, x, y, ok := parseTilePath(tt.path)
+		if z != tt.z || x != tt.x || y != tt.y || ok != tt.ok {
+			t.Errorf("%v: got %v/%v/%v %v", tt.path, z, x, y, ok)
+		}
+	}
+}
+
+func TestTilePosition(t *testing.T) {
+	tests := []struct {
+		lon    float64
+		x      int
+		tileX  int
+		onTile bool
+	}{
+		// Just west of the boundary at longitude 0, floored to -1 instead of truncated to the edge
+		{-0.0001, 1, -1, true},
+		{-0.0001, 0, mvt.Extent - 1, true},
+		{0, 1, 0, true},
+		// 14 pixels into the buffer of the tile to the east, then 43 pixels, beyond it
+		{-10, 1, -228, true},
+		{-30, 1, -683, false},
+	}
+
+	for _, tt := range tests {
+		tileX, _, onTile := tilePosition(0, tt.lon, 1, tt.x, 0)
+		if tileX != tt.tileX || onTile != tt.onTile {
+			t.Errorf("lon %v on tile %v: expected %v %v, got %v %v", tt.lon, tt.x, tt.tileX, tt.onTile, tileX, onTile)
+		}
+	}
+}
+
+func TestDripTileLayer(t *testing.T) {
+	serv := newTestServ(t)
+
+	layer := dripTileLayer(serv.DripsSlice, 0, 0, 0)
+	if len(layer.Features) != 1 || layer.Features[0].Properties["count"] != 3 {
+		t.Errorf("Expected a single cluster of 3 DRIPs at zoom 0, got %+v", layer.Features)
+	}
+
+	// The tile at zoom 14 holding the first test DRIP
+	layer = dripTileLayer(serv.DripsSlice, 14, 8383, 5404)
+	if len(layer.Features) != 1 || layer.Features[0].Properties["id"] != "ID_1" {
+		t.Errorf("Expected only ID_1 at zoom 14, got %+v", layer.Features)
+	}
+
+	recorder := httptest.NewRecorder()
+	createMux(serv).ServeHTTP(recorder, httptest.NewRequest("GET", "/tiles/0/0/0.mvt", nil))
+	assert(t, recorder.Code, 200)
+	assert(t, recorder.Header().Get("Content-Type"), "application/vnd.mapbox-vector-tile")
+}
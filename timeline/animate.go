@@ -0,0 +1,153 @@
+package timeline
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Time each frame is shown, whatever the time between the changes was
+const FrameDelay = time.Second
+
+// Pixels around the timestamp below each image
+const captionMargin = 4
+
+const timeFormat = "2006-01-02 15:04:05 -0700"
+
+var captionFace = basicfont.Face7x13
+var captionBackground = color.RGBA{0x30, 0x30, 0x30, 0xff}
+
+// Writes frames as an animated "gif" or "png" (APNG), images are read with load
+func Animate(w io.Writer, format string, frames []Frame, load func(hash string) ([]byte, error)) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to animate")
+	}
+
+	canvases, err := compose(frames, load)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "gif":
+		return writeGif(w, canvases)
+	case "png":
+		return writeApng(w, canvases)
+	}
+	return fmt.Errorf("unknown animation format %v, expected gif or png", format)
+}
+
+func caption(frame Frame) string {
+	if frame.Hash == "" {
+		return frame.Time.Format(timeFormat) + " geen beeld"
+	}
+	return frame.Time.Format(timeFormat)
+}
+
+// Draws every frame centered on canvases of the same size, with its time below it
+func compose(frames []Frame, load func(hash string) ([]byte, error)) ([]*image.RGBA, error) {
+	images := make(map[string]image.Image)
+	width, height := 0, 0
+
+	for _, frame := range frames {
+		if textWidth := len(caption(frame))*captionFace.Advance + 2*captionMargin; textWidth > width {
+			width = textWidth
+		}
+
+		if _, done := images[frame.Hash]; done || frame.Hash == "" {
+			continue
+		}
+
+		data, err := load(frame.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding timeline image %v: %w", frame.Hash, err)
+		}
+
+		images[frame.Hash] = img
+		width = max(width, img.Bounds().Dx())
+		height = max(height, img.Bounds().Dy())
+	}
+
+	captionTop := height
+	canvasHeight := height + captionFace.Height + 2*captionMargin
+
+	canvases := make([]*image.RGBA, len(frames))
+	for i, frame := range frames {
+		canvas := image.NewRGBA(image.Rect(0, 0, width, canvasHeight))
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+		if img, found := images[frame.Hash]; found {
+			bounds := img.Bounds()
+			offset := image.Pt((width-bounds.Dx())/2, (height-bounds.Dy())/2)
+			draw.Draw(canvas, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Over)
+		}
+
+		strip := image.Rect(0, captionTop, width, canvasHeight)
+		draw.Draw(canvas, strip, image.NewUniform(captionBackground), image.Point{}, draw.Src)
+
+		drawer := font.Drawer{Dst: canvas, Src: image.NewUniform(color.White), Face: captionFace}
+		drawer.Dot = fixed.P(captionMargin, captionTop+captionMargin+captionFace.Ascent)
+		drawer.DrawString(caption(frame))
+
+		canvases[i] = canvas
+	}
+
+	return canvases, nil
+}
+
+// Uses the exact colors when they fit in a GIF palette, which is common for panel images
+func gifPalette(canvases []*image.RGBA) (color.Palette, bool) {
+	seen := make(map[color.RGBA]bool)
+	colors := make(color.Palette, 0, 256)
+
+	for _, canvas := range canvases {
+		for i := 0; i < len(canvas.Pix); i += 4 {
+			c := color.RGBA{canvas.Pix[i], canvas.Pix[i+1], canvas.Pix[i+2], canvas.Pix[i+3]}
+			if seen[c] {
+				continue
+			}
+			if len(colors) == 256 {
+				return palette.Plan9, false
+			}
+			seen[c] = true
+			colors = append(colors, c)
+		}
+	}
+
+	return colors, true
+}
+
+func writeGif(w io.Writer, canvases []*image.RGBA) error {
+	colors, exact := gifPalette(canvases)
+	anim := &gif.GIF{}
+
+	for _, canvas := range canvases {
+		paletted := image.NewPaletted(canvas.Bounds(), colors)
+		if exact {
+			draw.Draw(paletted, paletted.Bounds(), canvas, image.Point{}, draw.Src)
+		} else {
+			draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), canvas, image.Point{})
+		}
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, int(FrameDelay/(10*time.Millisecond)))
+	}
+
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,132 @@
+package timeline
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	buf := &bytes.Buffer{}
+	png.Encode(buf, img)
+	return buf.Bytes()
+}
+
+func testStore() (*Store, []Frame) {
+	s := NewStore(0)
+	s.Record("ID_1", at(0), "red", solid(200, 64, color.RGBA{0xff, 0, 0, 0xff}))
+	s.Record("ID_1", at(5), "", nil)
+	s.Record("ID_1", at(10), "amber", solid(120, 96, color.RGBA{0xff, 0xb0, 0, 0xff}))
+	return s, s.Between("ID_1", at(0), at(60))
+}
+
+func TestAnimateGif(t *testing.T) {
+	s, frames := testStore()
+
+	buf := &bytes.Buffer{}
+	if err := Animate(buf, "gif", frames, s.Image); err != nil {
+		t.Fatal(err)
+	}
+
+	anim, err := gif.DecodeAll(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(anim.Image) != 3 {
+		t.Fatalf("expected 3 frames, got %v", len(anim.Image))
+	}
+
+	// Wide enough for the longest timestamp, tall enough for the tallest image plus the timestamp
+	bounds := anim.Image[0].Bounds()
+	captionWidth := len(caption(frames[1]))*captionFace.Advance + 2*captionMargin
+	if bounds.Dx() != captionWidth || bounds.Dy() != 96+captionFace.Height+2*captionMargin {
+		t.Errorf("unexpected size %v", bounds)
+	}
+
+	if anim.Delay[0] != 100 {
+		t.Errorf("expected a delay of 100, got %v", anim.Delay[0])
+	}
+
+	// The first frame shows the red image, the second one nothing
+	if r, _, _, _ := anim.Image[0].At(130, 48).RGBA(); r != 0xffff {
+		t.Errorf("expected red in the first frame, got %v", anim.Image[0].At(130, 48))
+	}
+	if r, _, _, _ := anim.Image[1].At(130, 48).RGBA(); r != 0 {
+		t.Errorf("expected black in the second frame, got %v", anim.Image[1].At(130, 48))
+	}
+}
+
+func TestAnimateApng(t *testing.T) {
+	s, frames := testStore()
+
+	buf := &bytes.Buffer{}
+	if err := Animate(buf, "png", frames, s.Image); err != nil {
+		t.Fatal(err)
+	}
+
+	// Viewers without APNG support show the first frame
+	first, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := first.At(130, 48).RGBA(); r != 0xffff {
+		t.Errorf("expected red in the first frame, got %v", first.At(130, 48))
+	}
+
+	chunks, err := readChunks(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := make([]string, 0)
+	sequence := uint32(0)
+	for _, c := range chunks {
+		if len(kinds) == 0 || kinds[len(kinds)-1] != c.kind {
+			kinds = append(kinds, c.kind)
+		}
+
+		switch c.kind {
+		case "acTL":
+			if frames := binary.BigEndian.Uint32(c.data); frames != 3 {
+				t.Errorf("expected 3 frames in acTL, got %v", frames)
+			}
+		case "fcTL", "fdAT":
+			if got := binary.BigEndian.Uint32(c.data); got != sequence {
+				t.Errorf("expected sequence number %v, got %v", sequence, got)
+			}
+			sequence++
+		}
+	}
+
+	want := []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "fcTL", "fdAT", "IEND"}
+	if len(kinds) != len(want) {
+		t.Fatalf("got chunks %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("got chunks %v, want %v", kinds, want)
+		}
+	}
+}
+
+func TestAnimateErrors(t *testing.T) {
+	s, frames := testStore()
+
+	if err := Animate(&bytes.Buffer{}, "webp", frames, s.Image); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	if err := Animate(&bytes.Buffer{}, "gif", nil, s.Image); err == nil {
+		t.Errorf("expected an error without frames")
+	}
+}
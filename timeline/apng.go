@@ -0,0 +1,121 @@
+package timeline
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"time"
+)
+
+// APNG adds an acTL chunk and a fcTL chunk per frame to a normal PNG, frames after the first
+// store their image data in fdAT chunks, see https://wiki.mozilla.org/APNG_Specification
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+type chunk struct {
+	kind string
+	data []byte
+}
+
+func readChunks(data []byte) ([]chunk, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("not a PNG")
+	}
+	data = data[len(pngSignature):]
+
+	chunks := make([]chunk, 0)
+	for len(data) >= 12 {
+		length := int(binary.BigEndian.Uint32(data))
+		if len(data) < 12+length {
+			return nil, fmt.Errorf("truncated PNG chunk")
+		}
+		chunks = append(chunks, chunk{string(data[4:8]), data[8 : 8+length]})
+		data = data[12+length:]
+	}
+
+	return chunks, nil
+}
+
+func writeChunk(w io.Writer, kind string, data []byte) error {
+	header := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	header = append(header, kind...)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	_, err := w.Write(binary.BigEndian.AppendUint32(append(header, data...), crc.Sum32()))
+	return err
+}
+
+func frameControl(sequence uint32, bounds image.Rectangle) []byte {
+	data := binary.BigEndian.AppendUint32(nil, sequence)
+	data = binary.BigEndian.AppendUint32(data, uint32(bounds.Dx()))
+	data = binary.BigEndian.AppendUint32(data, uint32(bounds.Dy()))
+	data = binary.BigEndian.AppendUint32(data, 0) // x offset
+	data = binary.BigEndian.AppendUint32(data, 0) // y offset
+	data = binary.BigEndian.AppendUint16(data, uint16(FrameDelay/time.Millisecond))
+	data = binary.BigEndian.AppendUint16(data, 1000)
+	return append(data, 0, 0) // No disposal and no blending, every frame covers the canvas
+}
+
+// Encodes every canvas as a PNG and moves its image data into animation chunks
+// All canvases have the same size and are opaque, so their IHDR chunks are equal
+func writeApng(w io.Writer, canvases []*image.RGBA) error {
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+
+	sequence := uint32(0)
+
+	for i, canvas := range canvases {
+		buf := &bytes.Buffer{}
+		if err := png.Encode(buf, canvas); err != nil {
+			return err
+		}
+
+		chunks, err := readChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			if err := writeChunk(w, "IHDR", chunks[0].data); err != nil {
+				return err
+			}
+
+			animationControl := binary.BigEndian.AppendUint32(nil, uint32(len(canvases)))
+			animationControl = binary.BigEndian.AppendUint32(animationControl, 0) // Loop forever
+			if err := writeChunk(w, "acTL", animationControl); err != nil {
+				return err
+			}
+		}
+
+		if err := writeChunk(w, "fcTL", frameControl(sequence, canvas.Bounds())); err != nil {
+			return err
+		}
+		sequence++
+
+		for _, c := range chunks {
+			if c.kind != "IDAT" {
+				continue
+			}
+
+			if i == 0 {
+				err = writeChunk(w, "IDAT", c.data)
+			} else {
+				err = writeChunk(w, "fdAT", append(binary.BigEndian.AppendUint32(nil, sequence), c.data...))
+				sequence++
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return writeChunk(w, "IEND", nil)
+}
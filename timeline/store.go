@@ -0,0 +1,256 @@
+// Package timeline keeps what each DRIP displayed over time and turns it into animations
+package timeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A DRIP started showing the image with Hash at Time
+// An empty Hash means the DRIP showed nothing from then on
+type Frame struct {
+	Id   string    `json:"id"`
+	Time time.Time `json:"time"`
+	Hash string    `json:"hash"`
+}
+
+// Keeps the frames of every DRIP, with image bytes stored once per hash
+// Frames of the retention period are kept in memory. Without a directory that's all there is,
+// with one every frame is also appended to frames.jsonl and images go to images/
+type Store struct {
+	sync.Mutex
+	retention time.Duration
+	dir       string
+	log       *os.File
+	frames    map[string][]Frame
+	images    map[string][]byte
+	// Memory holds every frame on display from since on, older ones are only on disk
+	since time.Time
+}
+
+const logFile = "frames.jsonl"
+const imageDir = "images"
+
+func NewStore(retention time.Duration) *Store {
+	return &Store{
+		retention: retention,
+		frames:    make(map[string][]Frame),
+		images:    make(map[string][]byte),
+	}
+}
+
+// Opens or creates a store in dir, which keeps every frame ever recorded
+// Only the frames of the retention period are loaded, older ones are read from disk when asked for
+func Open(dir string, retention time.Duration) (*Store, error) {
+	err := os.MkdirAll(filepath.Join(dir, imageDir), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating timeline directory: %w", err)
+	}
+
+	s := NewStore(retention)
+	s.dir = dir
+
+	// Frames replaced before the cutoff aren't loaded at all, so memory stays bounded by the retention period
+	now := time.Now()
+	cutoff := now.Add(-retention)
+	err = s.readLog(func(frame Frame) {
+		if retention != 0 && !frame.Time.After(cutoff) {
+			s.frames[frame.Id] = []Frame{frame}
+		} else {
+			s.frames[frame.Id] = append(s.frames[frame.Id], frame)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Prune(now)
+
+	s.log, err = os.OpenFile(filepath.Join(dir, logFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening timeline: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *Store) Close() error {
+	if s.log == nil {
+		return nil
+	}
+	return s.log.Close()
+}
+
+// Calls read with every frame in frames.jsonl, oldest first
+func (s *Store) readLog(read func(Frame)) error {
+	file, err := os.Open(filepath.Join(s.dir, logFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading timeline: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var frame Frame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			continue // Ignore lines cut off by a crash
+		}
+		read(frame)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading timeline: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) imagePath(hash string) string {
+	return filepath.Join(s.dir, imageDir, hash+".png")
+}
+
+// Records that DRIP id showed image at time t, nothing is recorded if it already did
+// A DRIP without frames showed nothing, so an empty hash is only recorded after an image
+func (s *Store) Record(id string, t time.Time, hash string, image []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	frames := s.frames[id]
+	last := ""
+	if len(frames) > 0 {
+		last = frames[len(frames)-1].Hash
+	}
+	if last == hash {
+		return nil
+	}
+
+	frame := Frame{Id: id, Time: t, Hash: hash}
+
+	if s.dir != "" {
+		if hash != "" {
+			if _, err := os.Stat(s.imagePath(hash)); errors.Is(err, fs.ErrNotExist) {
+				if err := os.WriteFile(s.imagePath(hash), image, 0644); err != nil {
+					return fmt.Errorf("error storing timeline image: %w", err)
+				}
+			}
+		}
+
+		line, err := json.Marshal(frame)
+		if err != nil {
+			return err
+		}
+		if _, err := s.log.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("error storing timeline frame: %w", err)
+		}
+	} else if hash != "" {
+		s.images[hash] = image
+	}
+
+	s.frames[id] = append(frames, frame)
+
+	return nil
+}
+
+// Drops frames that ended before the retention period and images no longer used
+// The frame shown at the start of the period is kept, as it was still on display
+// Pruning goes over every DRIP, so it's done once per update rather than on every Record
+func (s *Store) Prune(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.retention == 0 {
+		return
+	}
+
+	cutoff := now.Add(-s.retention)
+	s.since = cutoff
+	used := make(map[string]bool)
+
+	for id, frames := range s.frames {
+		start := 0
+		for start+1 < len(frames) && !frames[start+1].Time.After(cutoff) {
+			start++
+		}
+		frames = frames[start:]
+
+		if len(frames) == 1 && frames[0].Hash == "" && frames[0].Time.Before(cutoff) {
+			delete(s.frames, id)
+			continue
+		}
+
+		s.frames[id] = frames
+		for _, frame := range frames {
+			used[frame.Hash] = true
+		}
+	}
+
+	for hash := range s.images {
+		if !used[hash] {
+			delete(s.images, hash)
+		}
+	}
+}
+
+// Returns the frames of a DRIP shown in [from, to), oldest first
+// This includes the frame that was on display at from
+// Ranges starting before the frames kept in memory are read from frames.jsonl
+func (s *Store) Between(id string, from, to time.Time) []Frame {
+	s.Lock()
+	inMemory := s.dir == "" || !from.Before(s.since)
+	frames := s.frames[id]
+	s.Unlock()
+
+	if !inMemory {
+		frames = make([]Frame, 0)
+		err := s.readLog(func(frame Frame) {
+			if frame.Id == id {
+				frames = append(frames, frame)
+			}
+		})
+		if err != nil {
+			fmt.Println("Not reading timeline:", err)
+		}
+	}
+
+	out := make([]Frame, 0)
+	for i, frame := range frames {
+		if !frame.Time.Before(to) {
+			break
+		}
+
+		// Replaced before from, so not on display anymore
+		if i+1 < len(frames) && !frames[i+1].Time.After(from) {
+			continue
+		}
+
+		out = append(out, frame)
+	}
+
+	return out
+}
+
+// Returns the PNG bytes stored for hash
+func (s *Store) Image(hash string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.dir != "" {
+		return os.ReadFile(s.imagePath(hash))
+	}
+
+	image, found := s.images[hash]
+	if !found {
+		return nil, fmt.Errorf("no timeline image %v", hash)
+	}
+
+	return image, nil
+}
@@ -0,0 +1,168 @@
+package timeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
+
+func hashes(frames []Frame) []string {
+	out := make([]string, len(frames))
+	for i, frame := range frames {
+		out[i] = frame.Hash
+	}
+	return out
+}
+
+func TestRecordSkipsUnchanged(t *testing.T) {
+	s := NewStore(0)
+	s.Record("ID_1", at(-5), "", nil)
+	s.Record("ID_1", at(0), "a", []byte("A"))
+	s.Record("ID_1", at(5), "a", []byte("A"))
+	s.Record("ID_1", at(10), "b", []byte("B"))
+	s.Record("ID_1", at(15), "", nil)
+
+	frames := s.Between("ID_1", at(0), at(60))
+	if !reflect.DeepEqual(hashes(frames), []string{"a", "b", ""}) {
+		t.Errorf("got %v", hashes(frames))
+	}
+	if !frames[1].Time.Equal(at(10)) {
+		t.Errorf("expected the change at %v, got %v", at(10), frames[1].Time)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	s := NewStore(0)
+	for i, hash := range []string{"a", "b", "c", "d"} {
+		s.Record("ID_1", at(i*10), hash, []byte(hash))
+	}
+
+	cases := []struct {
+		from, to int
+		want     []string
+	}{
+		{0, 40, []string{"a", "b", "c", "d"}},
+		// b was on display at 15
+		{15, 25, []string{"b", "c"}},
+		{10, 20, []string{"b"}},
+		{50, 60, []string{"d"}},
+		{-20, -10, []string{}},
+	}
+
+	for _, c := range cases {
+		got := hashes(s.Between("ID_1", at(c.from), at(c.to)))
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v-%v: got %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+
+	if len(s.Between("UNKNOWN", at(0), at(40))) != 0 {
+		t.Errorf("expected no frames for an unknown DRIP")
+	}
+}
+
+func TestRetention(t *testing.T) {
+	s := NewStore(time.Hour)
+	s.Record("ID_1", at(0), "a", []byte("A"))
+	s.Record("ID_1", at(30), "b", []byte("B"))
+	s.Record("ID_1", at(100), "c", []byte("C"))
+	s.Prune(at(100))
+
+	// b was still on display an hour before the last update, a wasn't
+	if got := hashes(s.Between("ID_1", at(0), at(200))); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("got %v", got)
+	}
+
+	if _, err := s.Image("a"); err == nil {
+		t.Errorf("expected image a to be dropped")
+	}
+	if image, err := s.Image("b"); err != nil || string(image) != "B" {
+		t.Errorf("expected image b to be kept, got %q, %v", image, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Record("ID_1", at(0), "a", []byte("A"))
+	s.Record("ID_1", at(10), "b", []byte("B"))
+	s.Close()
+
+	s, err = Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The last frame is known after reopening, so it isn't stored twice
+	s.Record("ID_1", at(20), "b", []byte("B"))
+
+	if got := hashes(s.Between("ID_1", at(0), at(30))); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("got %v", got)
+	}
+	if image, err := s.Image("a"); err != nil || string(image) != "A" {
+		t.Errorf("expected image a, got %q, %v", image, err)
+	}
+}
+
+func TestOpenKeepsRetentionInMemory(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	s, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Record("ID_1", now.Add(-3*time.Hour), "a", []byte("A"))
+	s.Record("ID_1", now.Add(-2*time.Hour), "b", []byte("B"))
+	s.Record("ID_1", now.Add(-time.Minute), "c", []byte("C"))
+	s.Record("ID_2", now.Add(-3*time.Hour), "d", []byte("D"))
+	s.Record("ID_2", now.Add(-2*time.Hour), "", nil)
+	s.Prune(now)
+
+	// b was still on display at the start of the period, a and ID_2 ended before it
+	if got := hashes(s.frames["ID_1"]); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("expected only the retention period in memory, got %v", got)
+	}
+	if _, found := s.frames["ID_2"]; found {
+		t.Errorf("expected ID_2 to be dropped from memory")
+	}
+	s.Close()
+
+	s, err = Open(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if got := hashes(s.frames["ID_1"]); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("expected only the retention period to be loaded, got %v", got)
+	}
+	if _, found := s.frames["ID_2"]; found {
+		t.Errorf("expected ID_2 not to be loaded")
+	}
+
+	// Older ranges are still read from disk
+	if got := hashes(s.Between("ID_1", now.Add(-4*time.Hour), now)); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("got %v", got)
+	}
+	if got := hashes(s.Between("ID_2", now.Add(-4*time.Hour), now)); !reflect.DeepEqual(got, []string{"d", ""}) {
+		t.Errorf("got %v", got)
+	}
+	if got := hashes(s.Between("ID_1", now.Add(-30*time.Minute), now)); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("got %v", got)
+	}
+	if image, err := s.Image("a"); err != nil || string(image) != "A" {
+		t.Errorf("expected image a to stay on disk, got %q, %v", image, err)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hunternl/trafficmap/timeline"
+)
+
+// Default length of a timeline when from is left out
+const defaultTimelineRange = time.Hour
+
+var timelineContentTypes = map[string]string{
+	"gif": "image/gif",
+	"png": "image/apng",
+}
+
+// Lists what each DRIP shows, DRIPs that are gone show nothing from now on
+// Returns the frames with the images they show by hash, so they can be recorded after the server is unlocked
+func timelineFrames(previous map[string]Drip, drips []Drip, t time.Time) ([]timeline.Frame, map[string][]byte) {
+	frames := make([]timeline.Frame, 0, len(drips))
+	images := make(map[string][]byte)
+	current := make(map[string]bool, len(drips))
+
+	for _, drip := range drips {
+		current[drip.Id] = true
+		frames = append(frames, timeline.Frame{Id: drip.Id, Time: t, Hash: drip.ImageHash})
+		if drip.ImageHash != "" {
+			images[drip.ImageHash] = drip.image
+		}
+	}
+
+	for id := range previous {
+		if !current[id] {
+			frames = append(frames, timeline.Frame{Id: id, Time: t})
+		}
+	}
+
+	return frames, images
+}
+
+// Records frames from timelineFrames, a frame that fails to record doesn't stop the others
+func recordTimeline(store *timeline.Store, frames []timeline.Frame, images map[string][]byte, t time.Time) {
+	for _, frame := range frames {
+		if err := store.Record(frame.Id, frame.Time, frame.Hash, images[frame.Hash]); err != nil {
+			fmt.Println("Not recording timeline of "+frame.Id+":", err)
+		}
+	}
+
+	store.Prune(t)
+}
+
+// Serves /timelines/{id}.gif or /timelines/{id}.png (APNG) of what a DRIP showed
+// The optional from and to parameters pick the time range, by default the last hour
+func handleTimelines(serv *DripServ) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, format, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/timelines/"), ".")
+
+		contentType, found := timelineContentTypes[format]
+		if !found {
+			w.WriteHeader(404)
+			return
+		}
+
+		to, err := timeParam(r, "to", time.Now())
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		from, err := timeParam(r, "from", to.Add(-defaultTimelineRange))
+		if err != nil || !from.Before(to) {
+			w.WriteHeader(400)
+			w.Write([]byte("from should be an RFC 3339 time before to"))
+			return
+		}
+
+		frames := serv.timeline.Between(id, from, to)
+		if len(frames) == 0 {
+			w.WriteHeader(404)
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		err = timeline.Animate(buf, format, frames, serv.timeline.Image)
+		if err != nil {
+			fmt.Println(err.Error())
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+// Parses the -from and -to flags, by default the timeline covers the last day
+func parseTimelineRange(fromFlag, toFlag string) (time.Time, time.Time, error) {
+	to := time.Now()
+	if toFlag != "" {
+		t, err := time.Parse(time.RFC3339, toFlag)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("-to should be an RFC 3339 time")
+		}
+		to = t
+	}
+
+	from := to.Add(-time.Hour * 24)
+	if fromFlag != "" {
+		t, err := time.Parse(time.RFC3339, fromFlag)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("-from should be an RFC 3339 time")
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("-from should be before -to")
+	}
+
+	return from, to, nil
+}
+
+// Writes the timeline of a DRIP kept in historyDir to outDir as {id}.gif or {id}.png
+func writeTimeline(historyDir, id, format string, from, to time.Time, outDir string) error {
+	if historyDir == "" {
+		return fmt.Errorf("a timeline needs the -history directory the server recorded to")
+	}
+
+	if _, found := timelineContentTypes[format]; !found {
+		return fmt.Errorf("unknown timeline format %v, expected gif or png", format)
+	}
+
+	store, err := timeline.Open(historyDir, TimelineRetention)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	frames := store.Between(id, from, to)
+	if len(frames) == 0 {
+		return fmt.Errorf("nothing recorded for %v between %v and %v", id, from, to)
+	}
+
+	err = os.MkdirAll(outDir, 0755)
+	if err != nil {
+		return fmt.Errorf("error while ensuring output directory exists: %w", err)
+	}
+
+	fileName := filepath.Join(outDir, id+"."+format)
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating timeline file: %w", err)
+	}
+	defer file.Close()
+
+	err = timeline.Animate(file, format, frames, store.Image)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Written %v frames of %v to %v\n", len(frames), id, fileName)
+
+	return file.Close()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"image/gif"
+	"image/png"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hunternl/trafficmap/timeline"
+)
+
+func TestTimelines(t *testing.T) {
+	serv := newTestServ(t)
+	mux := createMux(serv)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	frames, images := timelineFrames(nil, serv.DripsSlice, start)
+	recordTimeline(serv.timeline, frames, images, start)
+
+	// ID_2 disappears from the feed, ID_1 keeps its text
+	previous := map[string]Drip{"ID_1": serv.dripsMap["ID_1"], "ID_2": serv.dripsMap["ID_2"]}
+	frames, images = timelineFrames(previous, []Drip{serv.dripsMap["ID_1"]}, start.Add(UpdateInterval))
+	recordTimeline(serv.timeline, frames, images, start.Add(UpdateInterval))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/timelines/ID_2.gif?from=2024-05-01T12:00:00Z&to=2024-05-01T13:00:00Z", nil))
+	assert(t, recorder.Code, 200)
+	assert(t, recorder.Header().Get("Content-Type"), "image/gif")
+
+	anim, err := gif.DecodeAll(bytes.NewReader(recorder.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, len(anim.Image), 2)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/timelines/ID_1.png?from=2024-05-01T12:00:00Z&to=2024-05-01T13:00:00Z", nil))
+	assert(t, recorder.Code, 200)
+	assert(t, recorder.Header().Get("Content-Type"), "image/apng")
+
+	cases := map[string]int{
+		"/timelines/ID_1.webp":                                                  404,
+		"/timelines/ID_3.gif?to=2024-05-01T13:00:00Z":                           404,
+		"/timelines/ID_1.gif?from=yesterday":                                    400,
+		"/timelines/ID_1.gif?from=2024-05-01T13:00:00Z&to=2024-05-01T12:00:00Z": 400,
+	}
+	for url, code := range cases {
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert(t, recorder.Code, code)
+	}
+}
+
+func TestWriteTimeline(t *testing.T) {
+	serv := newTestServ(t)
+	historyDir, outDir := t.TempDir(), t.TempDir()
+
+	store, err := timeline.Open(historyDir, TimelineRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := time.Now().Add(-time.Hour)
+	frames, images := timelineFrames(nil, serv.DripsSlice, recorded)
+	recordTimeline(store, frames, images, recorded)
+	store.Close()
+
+	from, to, err := parseTimelineRange("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeTimeline(historyDir, "ID_2", "png", from, to, outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(outDir, "ID_2.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := png.Decode(file); err != nil {
+		t.Errorf("expected the first frame to decode as PNG: %v", err)
+	}
+
+	if err := writeTimeline("", "ID_1", "gif", time.Time{}, time.Now(), t.TempDir()); err == nil {
+		t.Errorf("expected an error without a history directory")
+	}
+
+	if _, _, err := parseTimelineRange("2024-05-01T13:00:00Z", "2024-05-01T12:00:00Z"); err == nil {
+		t.Errorf("expected an error for from after to")
+	}
+}
+
+func TestRecordTimelineContinuesAfterErrors(t *testing.T) {
+	store, err := timeline.Open(t.TempDir(), TimelineRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// The image of ID_1 can't be written, ID_2 should still be recorded
+	recorded := time.Now()
+	frames := []timeline.Frame{
+		{Id: "ID_1", Time: recorded, Hash: "missing/directory"},
+		{Id: "ID_2", Time: recorded, Hash: "image"},
+	}
+	images := map[string][]byte{"missing/directory": []byte("A"), "image": []byte("B")}
+	recordTimeline(store, frames, images, recorded)
+
+	assert(t, len(store.Between("ID_1", recorded, recorded.Add(time.Minute))), 0)
+	assert(t, len(store.Between("ID_2", recorded, recorded.Add(time.Minute))), 1)
+}
@@ -0,0 +1,115 @@
+package traveltime
+
+import (
+	"sync"
+	"time"
+)
+
+// Routes shown by a panel at a given moment
+type Sample struct {
+	Time   time.Time `json:"time"`
+	Routes []Route   `json:"routes"`
+}
+
+// Keeps a bounded time series of advised travel times per DRIP
+type History struct {
+	sync.Mutex
+	maxSamples int
+	series     map[string][]Sample
+}
+
+func NewHistory(maxSamples int) *History {
+	return &History{
+		maxSamples: maxSamples,
+		series:     make(map[string][]Sample),
+	}
+}
+
+// Records the routes a DRIP showed at time t, dropping the oldest sample once full
+func (h *History) Add(id string, t time.Time, routes []Route) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.add(id, t, routes)
+}
+
+func (h *History) add(id string, t time.Time, routes []Route) {
+	if routes == nil {
+		routes = make([]Route, 0)
+	}
+
+	samples := append(h.series[id], Sample{Time: t, Routes: routes})
+	if len(samples) > h.maxSamples {
+		samples = samples[len(samples)-h.maxSamples:]
+	}
+
+	h.series[id] = samples
+}
+
+// Records the routes of every DRIP at time t, once per update
+// DRIPs that showed travel times before but are missing from routes or show none get an empty sample,
+// so their history doesn't look current. Series without any routes since cutoff are dropped
+func (h *History) Update(t time.Time, routes map[string][]Route, cutoff time.Time) {
+	h.Lock()
+	defer h.Unlock()
+
+	for id, shown := range routes {
+		if len(shown) > 0 {
+			h.add(id, t, shown)
+		}
+	}
+
+	for id := range h.series {
+		if len(routes[id]) == 0 {
+			h.add(id, t, nil)
+		}
+	}
+
+	for id, samples := range h.series {
+		start := 0
+		for start < len(samples) && samples[start].Time.Before(cutoff) {
+			start++
+		}
+		samples = samples[start:]
+
+		showsRoutes := false
+		for _, sample := range samples {
+			showsRoutes = showsRoutes || len(sample.Routes) > 0
+		}
+
+		if showsRoutes {
+			h.series[id] = samples
+		} else {
+			delete(h.series, id)
+		}
+	}
+}
+
+// Returns a copy of the recorded samples for the given DRIP, oldest first
+func (h *History) Get(id string) []Sample {
+	h.Lock()
+	defer h.Unlock()
+
+	samples := h.series[id]
+	out := make([]Sample, len(samples))
+	copy(out, samples)
+
+	return out
+}
+
+// Returns the samples of every DRIP recorded in [from, to), oldest first
+func (h *History) Between(from, to time.Time) map[string][]Sample {
+	h.Lock()
+	defer h.Unlock()
+
+	out := make(map[string][]Sample)
+	for id, samples := range h.series {
+		for _, sample := range samples {
+			if !sample.Time.Before(from) && sample.Time.Before(to) {
+				out[id] = append(out[id], sample)
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,168 @@
+package traveltime
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A single advised route as shown on a panel, like "UTRECHT via A12 25 min"
+type Route struct {
+	Target  string `json:"target"`
+	Via     string `json:"via"`
+	Minutes int    `json:"minutes"`
+}
+
+// Anything above this is more likely a year or a distance than a travel time
+const maxMinutes = 300
+
+var minuteWords = map[string]bool{
+	"min":     true,
+	"min.":    true,
+	"mins":    true,
+	"minuten": true,
+	"'":       true,
+}
+
+var roadPrefixes = map[rune]bool{
+	'A': true,
+	'N': true,
+	'S': true,
+	'E': true,
+}
+
+func isRoad(str string) bool {
+	if len(str) < 2 {
+		return false
+	}
+
+	for i, r := range strings.ToUpper(str) {
+		if i == 0 {
+			if !roadPrefixes[r] {
+				return false
+			}
+		} else if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parses a token like "25", "25min" or "25'" into minutes
+// Returns whether the token carried its own unit, so "25" alone can be told apart from "25 min"
+func parseMinutes(str string) (minutes int, hasUnit bool, ok bool) {
+	digits := strings.TrimRightFunc(str, func(r rune) bool { return !unicode.IsDigit(r) })
+	if digits == "" {
+		return 0, false, false
+	}
+
+	unit := strings.ToLower(str[len(digits):])
+	if unit != "" && !minuteWords[unit] {
+		return 0, false, false
+	}
+
+	num, err := strconv.Atoi(digits)
+	if err != nil || num < 0 {
+		return 0, false, false
+	}
+
+	return num, unit != "", true
+}
+
+// Parses a single line into a route
+// The target may be empty if the line only holds the via road and minutes,
+// in that case the caller fills it in from a preceding header line
+// Colons don't separate fields, so clock times like "22:00" are never taken for minutes
+func parseLine(line string) (route Route, ok bool) {
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '/' || r == '|'
+	})
+
+	route.Minutes = -1
+	explicitUnit := false
+	roadIndex := -1
+	targetWords := make([]string, 0, len(fields))
+
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		lower := strings.ToLower(field)
+
+		if lower == "via" && i+1 < len(fields) && isRoad(fields[i+1]) {
+			route.Via = strings.ToUpper(fields[i+1])
+			i++
+			roadIndex = i
+			continue
+		}
+
+		if route.Via == "" && isRoad(field) {
+			route.Via = strings.ToUpper(field)
+			roadIndex = i
+			continue
+		}
+
+		if minutes, hasUnit, isMinutes := parseMinutes(field); isMinutes {
+			// A bare number only counts if it is followed by a unit, or ends the line right after the road
+			// as in "DEN HAAG A4 22", so "A27 ZATERDAG 1" is not a travel time
+			nextIsUnit := i+1 < len(fields) && minuteWords[strings.ToLower(fields[i+1])]
+			travelTimeLayout := i == len(fields)-1 && i-1 == roadIndex
+			if hasUnit || nextIsUnit || travelTimeLayout {
+				route.Minutes = minutes
+				explicitUnit = hasUnit || nextIsUnit
+				if nextIsUnit {
+					i++
+				}
+				continue
+			}
+		}
+
+		if route.Minutes != -1 {
+			// Anything after the minutes is noise like "+5" or "file"
+			break
+		}
+
+		targetWords = append(targetWords, field)
+	}
+
+	route.Target = strings.Join(targetWords, " ")
+
+	// No travel time takes 0 minutes, such a number is part of a regular message
+	if route.Minutes <= 0 || route.Minutes > maxMinutes {
+		return Route{}, false
+	}
+
+	// Without a road, only trust numbers that are explicitly marked as minutes
+	if route.Via == "" && (!explicitUnit || route.Target == "") {
+		return Route{}, false
+	}
+
+	return route, true
+}
+
+// Extracts travel time comparisons from a panel's text lines
+// Lines without minutes are considered headers and name the target for the lines below them
+func Parse(lines []string) []Route {
+	var routes []Route
+	header := ""
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		route, ok := parseLine(line)
+		if !ok {
+			header = line
+			continue
+		}
+
+		if route.Target == "" {
+			route.Target = header
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes
+}
@@ -0,0 +1,151 @@
+package traveltime
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []Route
+	}{
+		{
+			name: "Parses a single line route",
+			args: []string{"UTRECHT via A12 25 min"},
+			want: []Route{
+				{Target: "UTRECHT", Via: "A12", Minutes: 25},
+			},
+		},
+		{
+			name: "Uses the header line as target",
+			args: []string{"ROTTERDAM", "via A4  18 min", "via A13  12 min"},
+			want: []Route{
+				{Target: "ROTTERDAM", Via: "A4", Minutes: 18},
+				{Target: "ROTTERDAM", Via: "A13", Minutes: 12},
+			},
+		},
+		{
+			name: "Handles roads without via and trailing numbers",
+			args: []string{"A'DAM  A10  15", "DEN HAAG  A4  22"},
+			want: []Route{
+				{Target: "A'DAM", Via: "A10", Minutes: 15},
+				{Target: "DEN HAAG", Via: "A4", Minutes: 22},
+			},
+		},
+		{
+			name: "Handles attached units",
+			args: []string{"SCHIPHOL A9 7min"},
+			want: []Route{
+				{Target: "SCHIPHOL", Via: "A9", Minutes: 7},
+			},
+		},
+		{
+			name: "Accepts routes without road when marked as minutes",
+			args: []string{"CENTRUM 8 min"},
+			want: []Route{
+				{Target: "CENTRUM", Via: "", Minutes: 8},
+			},
+		},
+		{
+			name: "Ignores regular messages",
+			args: []string{"WERK IN UITVOERING", "VANAF 2024", "A2 DICHT"},
+			want: nil,
+		},
+		{
+			name: "Ignores closures with clock times",
+			args: []string{"N201 AFGESLOTEN 22:00", "A12 DICHT VANAF 21:00", "AFRIT DICHT 22:00 - 05:00"},
+			want: nil,
+		},
+		{
+			name: "Ignores bare numbers outside the travel time layout",
+			args: []string{"A27 ZATERDAG 1"},
+			want: nil,
+		},
+		{
+			name: "Ignores zero minutes",
+			args: []string{"CENTRUM 0 min", "UTRECHT A12 0"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.args); !reflect.DeepEqual(got, tt.want) {
+				gotstr, _ := json.MarshalIndent(got, "", "\t")
+				wantstr, _ := json.MarshalIndent(tt.want, "", "\t")
+				t.Errorf("\nGot:\n%v\nExpected:\n%v", string(gotstr), string(wantstr))
+			}
+		})
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	history := NewHistory(2)
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		history.Add("ID_1", start.Add(time.Duration(i)*time.Minute), []Route{{Target: "UTRECHT", Via: "A12", Minutes: 20 + i}})
+	}
+
+	samples := history.Get("ID_1")
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, not %v", len(samples))
+	}
+
+	if samples[0].Routes[0].Minutes != 21 || samples[1].Routes[0].Minutes != 22 {
+		t.Errorf("Expected the oldest sample to be dropped, got %v", samples)
+	}
+
+	if len(history.Get("ID_2")) != 0 {
+		t.Errorf("Expected no samples for an unknown DRIP")
+	}
+}
+
+func TestHistoryBetween(t *testing.T) {
+	history := NewHistory(10)
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		history.Add("ID_1", start.Add(time.Duration(i)*time.Minute), []Route{{Target: "UTRECHT", Via: "A12", Minutes: 20 + i}})
+	}
+
+	samples := history.Between(start.Add(time.Minute), start.Add(time.Hour))["ID_1"]
+	if len(samples) != 2 || samples[0].Routes[0].Minutes != 21 {
+		t.Errorf("Expected the last 2 samples, got %v", samples)
+	}
+
+	if len(history.Between(start.Add(-time.Hour), start)) != 0 {
+		t.Errorf("Expected no samples before the first one")
+	}
+}
+
+func TestHistoryUpdate(t *testing.T) {
+	history := NewHistory(10)
+	start := time.Now()
+	route := []Route{{Target: "UTRECHT", Via: "A12", Minutes: 20}}
+
+	history.Update(start, map[string][]Route{"ID_1": route, "ID_2": route, "ID_3": nil}, start.Add(-time.Hour))
+
+	// ID_1 stops showing travel times and ID_2 disappears, both get an empty sample
+	history.Update(start.Add(time.Minute), map[string][]Route{"ID_1": nil}, start.Add(-time.Hour))
+
+	for _, id := range []string{"ID_1", "ID_2"} {
+		samples := history.Get(id)
+		if len(samples) != 2 || len(samples[1].Routes) != 0 {
+			t.Errorf("%v: expected an empty sample after the routes, got %v", id, samples)
+		}
+	}
+
+	if len(history.Get("ID_3")) != 0 {
+		t.Errorf("Expected no series for a DRIP that never showed travel times")
+	}
+
+	// Once the routes fall out of the retention window the series are dropped
+	history.Update(start.Add(2*time.Hour), map[string][]Route{}, start.Add(time.Hour))
+	if len(history.Get("ID_1")) != 0 || len(history.Get("ID_2")) != 0 {
+		t.Errorf("Expected series without routes to be dropped")
+	}
+}
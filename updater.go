@@ -1,61 +1,20 @@
 package main
 
 import (
-	"compress/gzip"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"path"
 	"time"
-)
-
-const dripStatusFile = "DRIPS.xml.gz"
-const dripLocationFile = "LocatietabelDRIPS.xml.gz"
-
-// Get a file from the given base url, optionally decompressing it
-// BaseURL is parsed and only the host(+port) and path is used
-// protocol is always set to http and the given filename is appended
-func getFile(baseUrl, filePath string, gZip bool) ([]byte, error) {
-	sourceURL, err := url.Parse(baseUrl)
-
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := http.Get("http://" + sourceURL.Host + "/" + path.Join(sourceURL.Path, filePath))
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
 
-	if response.StatusCode != 200 {
-		return nil, fmt.Errorf("server responded with %v", response.Status)
-	}
-
-	var reader io.ReadCloser
-
-	if gZip {
-		reader, err = gzip.NewReader(response.Body)
-	} else {
-		reader = response.Body
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	return io.ReadAll(reader)
-}
+	"github.com/hunternl/trafficmap/ndw"
+	"github.com/hunternl/trafficmap/traveltime"
+)
 
 func updateDrips(baseUrl string, serv *DripServ) error {
-	dripsFile, dripsErr := getFile(baseUrl, dripStatusFile, true)
+	dripsFile, dripsErr := ndw.GetFile(baseUrl, ndw.StatusFile, true)
 	if dripsErr != nil {
 		return fmt.Errorf("could not retrieve drip status file: %w", dripsErr)
 	}
 
-	locationsFile, locErr := getFile(baseUrl, dripLocationFile, true)
+	locationsFile, locErr := ndw.GetFile(baseUrl, ndw.LocationFile, true)
 	if locErr != nil {
 		return fmt.Errorf("could not retrieve drip location file: %w", locErr)
 	}
@@ -74,32 +33,61 @@ func updateDrips(baseUrl string, serv *DripServ) error {
 		}
 	}
 
-	// For description parsing development
-	// #TODO Seperate executable/submode?
-	// sb := bytes.Buffer{}
-
-	// for _, d := range allDrips {
-	// 	desc := description.Parse(d.Description)
-
-	// 	sb.WriteString(desc.Name)
-	// 	sb.WriteRune('\n')
-	// }
+	updated := time.Now()
 
-	// os.WriteFile("names.txt", sb.Bytes(), os.ModeAppend)
+	// Packing and encoding takes a while, so it's done before taking the lock
+	sprites, err := buildSprites(drips, updated)
+	if err != nil {
+		fmt.Println("Not updating sprites:", err)
+	}
 
 	serv.Lock()
-	defer serv.Unlock()
 
-	serv.LastUpdate = time.Now()
+	serv.LastUpdate = updated
 	serv.DripsSlice = drips
+	// On error the previous sheet keeps being served, its index still matches its own image
+	if sprites != nil {
+		serv.sprites = sprites
+	}
+
+	routes := make(map[string][]traveltime.Route, len(drips))
+	for _, drip := range drips {
+		routes[drip.Id] = drip.Routes
+	}
+	serv.travelTimes.Update(serv.LastUpdate, routes, serv.LastUpdate.Add(-TravelTimeRetention))
+
+	changes := diffDrips(serv.dripsMap, drips, serv.LastUpdate)
+	frames, frameImages := timelineFrames(serv.dripsMap, drips, serv.LastUpdate)
 
 	for k := range serv.dripsMap {
 		delete(serv.dripsMap, k)
 	}
 
+	for k := range serv.imagesByHash {
+		delete(serv.imagesByHash, k)
+	}
+
 	for _, drip := range drips {
 		serv.dripsMap[drip.Id] = drip
+		if drip.hasMarkerImage() {
+			serv.imagesByHash[drip.ImageHash] = drip.image
+		}
 	}
 
+	images := make(map[string][]byte, len(serv.imagesByHash))
+	for hash, image := range serv.imagesByHash {
+		images[hash] = image
+	}
+
+	serv.variants.retain(drips)
+	serv.updates.Publish(changes)
+	serv.Unlock()
+
+	// Writing frames may hit the disk, which shouldn't hold up requests
+	recordTimeline(serv.timeline, frames, frameImages, updated)
+
+	// Rendering variants takes a while, requests for them meanwhile wait on the render in progress
+	serv.variants.warm(images)
+
 	return nil
 }
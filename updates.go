@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// What changed between two update cycles
+type DripChanges struct {
+	Time time.Time
+	// DRIPs that are new or differ from the previous cycle
+	Changed []Drip
+	// Ids of DRIPs that are no longer shown
+	Removed []string
+}
+
+// Number of change sets a subscriber can lag behind before it is dropped
+const subscriberBuffer = 4
+
+// Fans out DripChanges from updateDrips to any number of listeners
+type updateBroadcaster struct {
+	sync.Mutex
+	subscribers map[chan DripChanges]bool
+}
+
+func newBroadcaster() *updateBroadcaster {
+	return &updateBroadcaster{
+		subscribers: make(map[chan DripChanges]bool),
+	}
+}
+
+func (b *updateBroadcaster) Subscribe() chan DripChanges {
+	b.Lock()
+	defer b.Unlock()
+
+	c := make(chan DripChanges, subscriberBuffer)
+	b.subscribers[c] = true
+
+	return c
+}
+
+// Stops sending to the given channel and closes it
+func (b *updateBroadcaster) Unsubscribe(c chan DripChanges) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.subscribers[c] {
+		delete(b.subscribers, c)
+		close(c)
+	}
+}
+
+// Sends changes to every subscriber without blocking
+// A subscriber whose buffer is full is unsubscribed rather than silently missing changes,
+// its channel is closed so the client reconnects and starts over from a full snapshot
+func (b *updateBroadcaster) Publish(changes DripChanges) {
+	b.Lock()
+	defer b.Unlock()
+
+	for c := range b.subscribers {
+		select {
+		case c <- changes:
+		default:
+			delete(b.subscribers, c)
+			close(c)
+		}
+	}
+}
+
+func diffDrips(previous map[string]Drip, current []Drip, t time.Time) DripChanges {
+	changes := DripChanges{
+		Time:    t,
+		Changed: make([]Drip, 0),
+		Removed: make([]string, 0),
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, drip := range current {
+		seen[drip.Id] = true
+
+		if old, found := previous[drip.Id]; !found || !reflect.DeepEqual(old, drip) {
+			changes.Changed = append(changes.Changed, drip)
+		}
+	}
+
+	for id := range previous {
+		if !seen[id] {
+			changes.Removed = append(changes.Removed, id)
+		}
+	}
+	sort.Strings(changes.Removed)
+
+	return changes
+}
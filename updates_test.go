@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishDropsSlowSubscriber(t *testing.T) {
+	b := newBroadcaster()
+	slow := b.Subscribe()
+	fast := b.Subscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.Publish(DripChanges{Time: time.UnixMilli(int64(i))})
+		<-fast
+	}
+
+	// The buffered change sets are still delivered, then the channel is closed
+	for i := 0; i < subscriberBuffer; i++ {
+		changes, ok := <-slow
+		assert(t, ok, true)
+		assert(t, changes.Time, time.UnixMilli(int64(i)))
+	}
+	_, ok := <-slow
+	assert(t, ok, false)
+
+	b.Lock()
+	assert(t, len(b.subscribers), 1)
+	b.Unlock()
+
+	// Unsubscribing after being dropped is harmless
+	b.Unsubscribe(slow)
+	b.Unsubscribe(fast)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/hunternl/trafficmap/webp"
+	"golang.org/x/image/draw"
+)
+
+// Largest width a DRIP image can be scaled to
+const maxVariantWidth = 2048
+
+// Widths variants are rendered at, a requested width is rounded up to the next one
+// This keeps the number of variants per image small, whatever widths clients ask for
+var variantWidths = []int{16, 24, 32, 48, 64, 96, 128, 192, 256, 384, 512, 768, 1024, 1536, maxVariantWidth}
+
+// Total size of the cached variants, the oldest are dropped beyond it
+const maxVariantBytes = 64 << 20
+
+var imageContentTypes = map[string]string{
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"webp": "image/webp",
+}
+
+// Variants rendered for every image during the update, so the first requests for them don't wait on rendering
+// Thumbnails for mobile clients in every format, and the original size as JPEG and WebP
+var warmVariants = []variantKey{
+	{format: "png", width: 64},
+	{format: "jpg", width: 64},
+	{format: "webp", width: 64},
+	{format: "jpg"},
+	{format: "webp"},
+}
+
+type variantKey struct {
+	hash   string
+	format string
+	width  int
+}
+
+// Keeps scaled and converted images, so each is only rendered once per source image
+type variantCache struct {
+	sync.Mutex
+	variants map[variantKey]*variant
+	// Rendered variants, oldest first, with their total size
+	order    []variantKey
+	size     int
+	maxBytes int
+}
+
+// Closes done once rendering finished, requests for the same variant wait on it
+type variant struct {
+	done  chan struct{}
+	bytes []byte
+	err   error
+}
+
+func newVariantCache() *variantCache {
+	return &variantCache{variants: make(map[variantKey]*variant), maxBytes: maxVariantBytes}
+}
+
+// Returns the cached variant, rendering it first if needed
+// Rendering happens outside the lock, so other variants are served meanwhile
+func (c *variantCache) get(key variantKey, render func() ([]byte, error)) ([]byte, error) {
+	c.Lock()
+	v, found := c.variants[key]
+	if !found {
+		v = &variant{done: make(chan struct{})}
+		c.variants[key] = v
+	}
+	c.Unlock()
+
+	if found {
+		<-v.done
+		return v.bytes, v.err
+	}
+
+	v.bytes, v.err = render()
+	close(v.done)
+
+	c.Lock()
+	defer c.Unlock()
+
+	// Failed variants aren't kept, so a later request tries again
+	if c.variants[key] != v {
+		return v.bytes, v.err
+	}
+	if v.err != nil {
+		delete(c.variants, key)
+		return v.bytes, v.err
+	}
+
+	c.order = append(c.order, key)
+	c.size += len(v.bytes)
+	for c.size > c.maxBytes && len(c.order) > 1 {
+		c.size -= len(c.variants[c.order[0]].bytes)
+		delete(c.variants, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	return v.bytes, v.err
+}
+
+// Drops variants of images that are no longer shown by any DRIP
+func (c *variantCache) retain(drips []Drip) {
+	hashes := make(map[string]bool, len(drips))
+	for _, drip := range drips {
+		hashes[drip.ImageHash] = true
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	order := make([]variantKey, 0, len(c.order))
+	for _, key := range c.order {
+		if hashes[key.hash] {
+			order = append(order, key)
+		} else {
+			c.size -= len(c.variants[key].bytes)
+		}
+	}
+	c.order = order
+
+	// Also drops variants still being rendered, get won't keep those once done
+	for key := range c.variants {
+		if !hashes[key.hash] {
+			delete(c.variants, key)
+		}
+	}
+}
+
+// Renders warmVariants of the given images by hash, those already cached are kept
+func (c *variantCache) warm(images map[string][]byte) {
+	for hash, image := range images {
+		for _, key := range warmVariants {
+			key.hash = hash
+			_, err := c.get(key, func() ([]byte, error) {
+				return renderVariant(image, key.format, key.width)
+			})
+			if err != nil {
+				fmt.Println("Not warming image variant:", err)
+			}
+		}
+	}
+}
+
+// Reads the w query parameter, rounded up to one of variantWidths, 0 means the original width
+func parseVariantWidth(str string) (int, error) {
+	if str == "" {
+		return 0, nil
+	}
+
+	width, err := strconv.Atoi(str)
+	if err != nil || width < 1 || width > maxVariantWidth {
+		return 0, fmt.Errorf("w should be an integer from 1 to %v", maxVariantWidth)
+	}
+
+	i := sort.SearchInts(variantWidths, width)
+	return variantWidths[i], nil
+}
+
+// Scales a PNG to the given width, keeping its aspect ratio, and encodes it as format
+// Upscaling uses nearest neighbour so the LED pixels stay crisp, downscaling smooths them out
+func renderVariant(src []byte, format string, width int) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	if width == 0 {
+		width = bounds.Dx()
+	}
+	height := (bounds.Dy()*width + bounds.Dx()/2) / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// JPEG has no transparency, DRIPs have a black background
+	if format == "jpg" {
+		draw.Draw(scaled, scaled.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	}
+
+	var scaler draw.Scaler = draw.CatmullRom
+	if width >= bounds.Dx() {
+		scaler = draw.NearestNeighbor
+	}
+	scaler.Scale(scaled, scaled.Bounds(), img, bounds, draw.Over, nil)
+
+	buf := &bytes.Buffer{}
+	switch format {
+	case "png":
+		err = png.Encode(buf, scaled)
+	case "jpg":
+		err = jpeg.Encode(buf, scaled, &jpeg.Options{Quality: 90})
+	case "webp":
+		err = webp.Encode(buf, scaled)
+	default:
+		err = fmt.Errorf("unsupported image format %v", format)
+	}
+
+	return buf.Bytes(), err
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestImageVariants(t *testing.T) {
+	serv := newTestServ(t)
+	mux := createMux(serv)
+
+	tests := []struct {
+		url         string
+		status      int
+		contentType string
+		width       int
+		height      int
+	}{
+		{"/img/{hash}.png", 200, "image/png", 40, 40},
+		{"/img/{hash}.png?w=24", 200, "image/png", 24, 24},
+		{"/img/{hash}.png?w=20", 200, "image/png", 24, 24},
+		{"/img/{hash}.png?w=160", 200, "image/png", 192, 192},
+		{"/img/{hash}.jpg?w=64", 200, "image/jpeg", 64, 64},
+		{"/img/{hash}.jpg", 200, "image/jpeg", 40, 40},
+		{"/img/{hash}.webp?w=20", 200, "image/webp", 24, 24},
+		{"/img/{hash}.webp", 200, "image/webp", 40, 40},
+		{"/img/{hash}.png?w=0", 400, "", 0, 0},
+		{"/img/{hash}.png?w=big", 400, "", 0, 0},
+		{"/img/{hash}.gif", 404, "", 0, 0},
+		{"/img/0000.png", 404, "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		tt.url = strings.ReplaceAll(tt.url, "{hash}", serv.dripsMap["ID_2"].ImageHash)
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", tt.url, nil))
+
+		if recorder.Code != tt.status {
+			t.Errorf("%v: expected status %v, got %v", tt.url, tt.status, recorder.Code)
+			continue
+		}
+		if tt.status != 200 {
+			continue
+		}
+
+		if contentType := recorder.Header().Get("Content-Type"); contentType != tt.contentType {
+			t.Errorf("%v: expected %v, got %v", tt.url, tt.contentType, contentType)
+		}
+		if width := recorder.Header().Get("X-Image-Width"); width != strconv.Itoa(tt.width) {
+			t.Errorf("%v: expected X-Image-Width %v, got %v", tt.url, tt.width, width)
+		}
+
+		var img image.Image
+		var err error
+		switch tt.contentType {
+		case "image/png":
+			img, err = png.Decode(bytes.NewReader(recorder.Body.Bytes()))
+		case "image/jpeg":
+			img, err = jpeg.Decode(bytes.NewReader(recorder.Body.Bytes()))
+		case "image/webp":
+			img, err = webp.Decode(bytes.NewReader(recorder.Body.Bytes()))
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tt.url, err)
+			continue
+		}
+
+		if img.Bounds().Dx() != tt.width || img.Bounds().Dy() != tt.height {
+			t.Errorf("%v: expected %vx%v, got %v", tt.url, tt.width, tt.height, img.Bounds())
+		}
+	}
+
+	// Six variants were rendered, w=20 is rounded up to the one for w=24
+	// They go once no DRIP shows the image
+	assert(t, len(serv.variants.variants), 6)
+	serv.variants.retain(serv.DripsSlice[:1])
+	assert(t, len(serv.variants.variants), 0)
+}
+
+func TestImageRedirect(t *testing.T) {
+	serv := newTestServ(t)
+	mux := createMux(serv)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/images/ID_2.jpg?w=64", nil))
+
+	assert(t, recorder.Code, 302)
+	assert(t, recorder.Header().Get("Location"), "/img/"+serv.dripsMap["ID_2"].ImageHash+".jpg?w=64")
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/img/"+serv.dripsMap["ID_2"].ImageHash+".png", nil))
+	assert(t, recorder.Header().Get("Cache-Control"), "public, max-age=31536000, immutable")
+
+	for _, url := range []string{"/images/ID_3.png", "/images/UNKNOWN_ID.png", "/images/ID_2.gif"} {
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert(t, recorder.Code, 404)
+	}
+}
+
+func TestVariantCacheRendersOnce(t *testing.T) {
+	cache := newVariantCache()
+	key := variantKey{"hash", "png", 20}
+
+	var renders atomic.Int32
+	release := make(chan struct{})
+	render := func() ([]byte, error) {
+		renders.Add(1)
+		<-release
+		return []byte("variant"), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if variant, err := cache.get(key, render); err != nil || string(variant) != "variant" {
+				t.Errorf("Expected the rendered variant, got %q, %v", variant, err)
+			}
+		}()
+	}
+
+	// Other variants don't wait for the one being rendered
+	for renders.Load() == 0 {
+		runtime.Gosched()
+	}
+	if _, err := cache.get(variantKey{"hash", "jpg", 20}, func() ([]byte, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	wg.Wait()
+	assert(t, renders.Load(), int32(1))
+
+	// Errors are passed on but not kept
+	failure := errors.New("failed")
+	_, err := cache.get(variantKey{"hash", "png", 40}, func() ([]byte, error) { return nil, failure })
+	assert(t, err, failure)
+	assert(t, len(cache.variants), 2)
+}
+
+func TestVariantCacheLimit(t *testing.T) {
+	cache := newVariantCache()
+	cache.maxBytes = 10
+
+	for width := 1; width <= 4; width++ {
+		if _, err := cache.get(variantKey{"hash", "png", width}, func() ([]byte, error) { return make([]byte, 4), nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only the two newest fit
+	assert(t, len(cache.variants), 2)
+	assert(t, cache.size, 8)
+	if _, found := cache.variants[variantKey{"hash", "png", 4}]; !found {
+		t.Errorf("Expected the newest variant to be kept")
+	}
+
+	cache.retain(nil)
+	assert(t, len(cache.variants), 0)
+	assert(t, cache.size, 0)
+}
+
+func TestWarmVariants(t *testing.T) {
+	serv := newTestServ(t)
+	serv.variants.warm(serv.imagesByHash)
+
+	assert(t, len(serv.variants.variants), len(serv.imagesByHash)*len(warmVariants))
+
+	// Warmed variants are served without rendering again
+	for hash := range serv.imagesByHash {
+		for _, key := range warmVariants {
+			key.hash = hash
+			_, err := serv.variants.get(key, func() ([]byte, error) {
+				t.Errorf("expected %v to be warmed", key)
+				return nil, nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// Package webp writes images as lossless WebP
+// Only what the image variants need is supported: no transforms and no color cache,
+// pixels are prefix coded with runs of the previous pixel as back references,
+// which keeps the mostly black DRIP images small
+package webp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// Largest width and height a VP8L header can hold
+const maxSize = 1 << 14
+
+// Longest back reference VP8L allows
+const maxRun = 4096
+
+// Shorter runs cost more as a back reference than as literals
+const minRun = 3
+
+const (
+	numLiterals = 256
+	numLengths  = 24
+	numDistance = 40
+)
+
+// Longest code of the pixel and code length alphabets
+const (
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+)
+
+// Order the code lengths of the code length alphabet are written in
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// Distance code of the pixel to the left, the first 120 codes are offsets in the plane
+const leftDistanceCode = 2
+
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits uint
+}
+
+// Writes the low n bits of v, least significant first
+func (w *bitWriter) write(v uint32, n uint) {
+	w.bits |= uint64(v) << w.nBits
+	w.nBits += n
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nBits = 0, 0
+	}
+	return w.buf
+}
+
+// Canonical prefix code, with the codes bit reversed as VP8L reads them
+type prefixCode struct {
+	codes  []uint32
+	widths []uint
+}
+
+func (w *bitWriter) writeSymbol(code prefixCode, symbol int) {
+	w.write(code.codes[symbol], code.widths[symbol])
+}
+
+// A value coded as a prefix symbol and extra bits, used for lengths and distances
+type prefixValue struct {
+	symbol    int
+	extraBits uint
+	extra     uint32
+}
+
+func toPrefixValue(v int) prefixValue {
+	d := v - 1
+	if d < 4 {
+		return prefixValue{symbol: d}
+	}
+
+	highest := 0
+	for d>>(highest+1) != 0 {
+		highest++
+	}
+	second := (d >> (highest - 1)) & 1
+	extraBits := uint(highest - 1)
+
+	return prefixValue{
+		symbol:    2*highest + second,
+		extraBits: extraBits,
+		extra:     uint32(d) & (1<<extraBits - 1),
+	}
+}
+
+// A literal pixel, or a run copying the previous pixel
+type token struct {
+	argb uint32
+	run  int
+}
+
+// Writes m to w as a lossless WebP
+func Encode(w io.Writer, m image.Image) error {
+	bounds := m.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > maxSize || height > maxSize {
+		return fmt.Errorf("can't encode a %vx%v image as WebP", width, height)
+	}
+
+	pixels := make([]uint32, 0, width*height)
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(m.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+			hasAlpha = hasAlpha || c.A != 0xff
+		}
+	}
+
+	tokens := tokenize(pixels)
+
+	green := make([]int, numLiterals+numLengths)
+	red := make([]int, numLiterals)
+	blue := make([]int, numLiterals)
+	alpha := make([]int, numLiterals)
+	distance := make([]int, numDistance)
+	for _, t := range tokens {
+		if t.run > 0 {
+			green[numLiterals+toPrefixValue(t.run).symbol]++
+			distance[toPrefixValue(leftDistanceCode).symbol]++
+			continue
+		}
+		green[t.argb>>8&0xff]++
+		red[t.argb>>16&0xff]++
+		blue[t.argb&0xff]++
+		alpha[t.argb>>24]++
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // Version
+	bw.write(0, 1) // No transforms
+	bw.write(0, 1) // No color cache
+	bw.write(0, 1) // A single set of prefix codes for the whole image
+
+	greenCode := bw.writePrefixCode(green)
+	redCode := bw.writePrefixCode(red)
+	blueCode := bw.writePrefixCode(blue)
+	alphaCode := bw.writePrefixCode(alpha)
+	distanceCode := bw.writePrefixCode(distance)
+
+	for _, t := range tokens {
+		if t.run > 0 {
+			length := toPrefixValue(t.run)
+			bw.writeSymbol(greenCode, numLiterals+length.symbol)
+			bw.write(length.extra, length.extraBits)
+
+			dist := toPrefixValue(leftDistanceCode)
+			bw.writeSymbol(distanceCode, dist.symbol)
+			bw.write(dist.extra, dist.extraBits)
+			continue
+		}
+		bw.writeSymbol(greenCode, int(t.argb>>8&0xff))
+		bw.writeSymbol(redCode, int(t.argb>>16&0xff))
+		bw.writeSymbol(blueCode, int(t.argb&0xff))
+		bw.writeSymbol(alphaCode, int(t.argb>>24))
+	}
+
+	data := bw.flush()
+	chunkSize := len(data)
+	padded := chunkSize + chunkSize&1
+
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+padded))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(chunkSize))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if chunkSize != padded {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// Replaces repeats of the previous pixel by runs
+func tokenize(pixels []uint32) []token {
+	tokens := make([]token, 0, len(pixels))
+	for i := 0; i < len(pixels); {
+		run := 0
+		if i > 0 {
+			for i+run < len(pixels) && run < maxRun && pixels[i+run] == pixels[i-1] {
+				run++
+			}
+		}
+
+		if run >= minRun {
+			tokens = append(tokens, token{run: run})
+			i += run
+			continue
+		}
+
+		tokens = append(tokens, token{argb: pixels[i]})
+		i++
+	}
+	return tokens
+}
+
+// Writes a prefix code for the symbol counts and returns it
+func (w *bitWriter) writePrefixCode(counts []int) prefixCode {
+	symbols := make([]int, 0)
+	for symbol, count := range counts {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	code := prefixCode{codes: make([]uint32, len(counts)), widths: make([]uint, len(counts))}
+
+	// One or two 8 bit symbols fit the simple code, a single symbol takes no bits at all
+	if len(symbols) <= 2 && (len(symbols) == 0 || symbols[len(symbols)-1] < 256) {
+		if len(symbols) == 0 {
+			symbols = append(symbols, 0)
+		}
+
+		w.write(1, 1)
+		w.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			w.write(0, 1)
+			w.write(uint32(symbols[0]), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(symbols[0]), 8)
+		}
+		if len(symbols) == 2 {
+			w.write(uint32(symbols[1]), 8)
+			code.codes[symbols[1]] = 1
+			code.widths[symbols[0]], code.widths[symbols[1]] = 1, 1
+		}
+
+		return code
+	}
+
+	lengths := huffmanLengths(counts, maxCodeLength)
+	w.write(0, 1)
+	w.writeCodeLengths(lengths)
+
+	return canonicalCode(lengths)
+}
+
+// Writes the code lengths of a normal prefix code, with runs of zeros as codes 17 and 18
+func (w *bitWriter) writeCodeLengths(lengths []int) {
+	tokens := make([]prefixValue, 0, len(lengths))
+	for i := 0; i < len(lengths); {
+		zeros := 0
+		for i+zeros < len(lengths) && lengths[i+zeros] == 0 && zeros < 138 {
+			zeros++
+		}
+
+		switch {
+		case zeros >= 11:
+			tokens = append(tokens, prefixValue{symbol: 18, extraBits: 7, extra: uint32(zeros - 11)})
+			i += zeros
+		case zeros >= 3:
+			tokens = append(tokens, prefixValue{symbol: 17, extraBits: 3, extra: uint32(zeros - 3)})
+			i += zeros
+		default:
+			tokens = append(tokens, prefixValue{symbol: lengths[i]})
+			i++
+		}
+	}
+
+	counts := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		counts[t.symbol]++
+	}
+	codeLengthLengths := huffmanLengths(counts, maxCodeLengthCodeLength)
+
+	n := len(codeLengthCodeOrder)
+	for n > 4 && codeLengthLengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+	w.write(uint32(n-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:n] {
+		w.write(uint32(codeLengthLengths[symbol]), 3)
+	}
+	w.write(0, 1) // Code lengths for every symbol follow
+
+	code := canonicalCode(codeLengthLengths)
+	for _, t := range tokens {
+		w.writeSymbol(code, t.symbol)
+		w.write(t.extra, t.extraBits)
+	}
+}
+
+// Assigns canonical codes to the lengths, a code with a single symbol takes no bits
+func canonicalCode(lengths []int) prefixCode {
+	code := prefixCode{codes: make([]uint32, len(lengths)), widths: make([]uint, len(lengths))}
+
+	used := 0
+	counts := make([]uint32, maxCodeLength+1)
+	for _, length := range lengths {
+		if length > 0 {
+			used++
+			counts[length]++
+		}
+	}
+	if used <= 1 {
+		return code
+	}
+
+	next := make([]uint32, maxCodeLength+1)
+	current := uint32(0)
+	for length := 1; length <= maxCodeLength; length++ {
+		current = (current + counts[length-1]) << 1
+		next[length] = current
+	}
+
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		c := next[length]
+		next[length]++
+
+		reversed := uint32(0)
+		for i := 0; i < length; i++ {
+			reversed = reversed<<1 | (c>>i)&1
+		}
+		code.codes[symbol] = reversed
+		code.widths[symbol] = uint(length)
+	}
+
+	return code
+}
+
+type huffmanNode struct {
+	weight int
+	parent int
+}
+
+// Returns Huffman code lengths of at most maxLength bits for the symbol counts
+// Rare symbols are counted as more common until the lengths fit, which keeps the code complete
+func huffmanLengths(counts []int, maxLength int) []int {
+	lengths := make([]int, len(counts))
+
+	symbols := make([]int, 0)
+	for symbol, count := range counts {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 1 {
+		lengths[symbols[0]] = 1
+	}
+	if len(symbols) <= 1 {
+		return lengths
+	}
+
+	for minWeight := 1; ; minWeight *= 2 {
+		nodes := make([]huffmanNode, len(symbols), 2*len(symbols)-1)
+		for i, symbol := range symbols {
+			nodes[i] = huffmanNode{weight: max(counts[symbol], minWeight), parent: -1}
+		}
+
+		// Merged nodes are created in order of weight, so two sorted queues replace a heap
+		leaves := make([]int, len(symbols))
+		for i := range leaves {
+			leaves[i] = i
+		}
+		sort.SliceStable(leaves, func(a, b int) bool { return nodes[leaves[a]].weight < nodes[leaves[b]].weight })
+		merged := make([]int, 0, len(symbols)-1)
+
+		lightest := func() int {
+			if len(merged) == 0 || (len(leaves) > 0 && nodes[leaves[0]].weight <= nodes[merged[0]].weight) {
+				n := leaves[0]
+				leaves = leaves[1:]
+				return n
+			}
+			n := merged[0]
+			merged = merged[1:]
+			return n
+		}
+
+		for len(leaves)+len(merged) > 1 {
+			a, b := lightest(), lightest()
+			nodes = append(nodes, huffmanNode{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+			nodes[a].parent, nodes[b].parent = len(nodes)-1, len(nodes)-1
+			merged = append(merged, len(nodes)-1)
+		}
+
+		longest := 0
+		for i, symbol := range symbols {
+			depth := 0
+			for n := i; nodes[n].parent != -1; n = nodes[n].parent {
+				depth++
+			}
+			lengths[symbol] = depth
+			longest = max(longest, depth)
+		}
+
+		if longest <= maxLength {
+			return lengths
+		}
+	}
+}
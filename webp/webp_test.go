@@ -0,0 +1,115 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func roundTrip(t *testing.T, m image.Image) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, m); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+
+	if decoded.Bounds().Size() != m.Bounds().Size() {
+		t.Fatalf("expected size %v, got %v", m.Bounds().Size(), decoded.Bounds().Size())
+	}
+
+	min := m.Bounds().Min
+	for y := 0; y < m.Bounds().Dy(); y++ {
+		for x := 0; x < m.Bounds().Dx(); x++ {
+			want := color.NRGBAModel.Convert(m.At(min.X+x, min.Y+y))
+			got := color.NRGBAModel.Convert(decoded.At(x, y))
+			if want != got {
+				t.Fatalf("pixel %v,%v: expected %v, got %v", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestEncodeDripImage(t *testing.T) {
+	// Black with a red border and amber text, like most DRIP images
+	m := image.NewNRGBA(image.Rect(0, 0, 200, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 200; x++ {
+			c := color.NRGBA{A: 0xff}
+			switch {
+			case x < 4 || y < 4 || x >= 196 || y >= 60:
+				c = color.NRGBA{R: 0xff, A: 0xff}
+			case (x/3+y/5)%7 == 0:
+				c = color.NRGBA{R: 0xff, G: 0xb0, A: 0xff}
+			}
+			m.SetNRGBA(x, y, c)
+		}
+	}
+	roundTrip(t, m)
+
+	buf := &bytes.Buffer{}
+	Encode(buf, m)
+	if buf.Len() > 2000 {
+		t.Errorf("expected runs to keep the image small, got %v bytes", buf.Len())
+	}
+}
+
+func TestEncodeManyColors(t *testing.T) {
+	// Every channel value occurs, with skewed counts and transparency
+	m := image.NewNRGBA(image.Rect(10, 20, 10+300, 20+90))
+	for y := 20; y < 110; y++ {
+		for x := 10; x < 310; x++ {
+			v := uint8((x*x + 7*y) % 256)
+			if (x+y)%5 == 0 {
+				v = uint8(x * y)
+			}
+			m.SetNRGBA(x, y, color.NRGBA{R: v, G: v ^ 0x5a, B: uint8(x + y), A: uint8(255 - y)})
+		}
+	}
+	roundTrip(t, m)
+}
+
+func TestEncodeSmall(t *testing.T) {
+	roundTrip(t, image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+
+	m := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	m.SetNRGBA(1, 0, color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff})
+	m.SetNRGBA(2, 1, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	roundTrip(t, m)
+
+	if err := Encode(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, maxSize+1, 1))); err == nil {
+		t.Errorf("expected an error for an image wider than %v", maxSize)
+	}
+}
+
+func TestHuffmanLengthsLimited(t *testing.T) {
+	// Fibonacci counts give the deepest possible tree
+	counts := make([]int, 30)
+	a, b := 1, 1
+	for i := range counts {
+		counts[i] = a
+		a, b = b, a+b
+	}
+
+	lengths := huffmanLengths(counts, maxCodeLength)
+
+	// A complete code fills the code space exactly
+	sum := 0
+	for _, length := range lengths {
+		if length < 1 || length > maxCodeLength {
+			t.Fatalf("expected lengths from 1 to %v, got %v", maxCodeLength, lengths)
+		}
+		sum += 1 << (maxCodeLength - length)
+	}
+	if sum != 1<<maxCodeLength {
+		t.Errorf("expected a complete code, got %v", lengths)
+	}
+}
@@ -2,12 +2,20 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/xml"
+	"image"
 	"image/png"
 	"time"
 
+	"github.com/hunternl/trafficmap/coordinate"
 	"github.com/hunternl/trafficmap/description"
+	"github.com/hunternl/trafficmap/imageinfo"
+	"github.com/hunternl/trafficmap/ndw"
+	"github.com/hunternl/trafficmap/textpanel"
+	"github.com/hunternl/trafficmap/traveltime"
 )
 
 type vms struct {
@@ -18,28 +26,6 @@ type vms struct {
 	Text           []string
 }
 
-type location struct {
-	Id          string `xml:"id,attr"`
-	Description string `xml:"vmsRecord>vmsRecord>vmsDescription>values>value"`
-	Latitude    string `xml:"vmsRecord>vmsRecord>vmsLocation>locationForDisplay>latitude"`
-	Longitude   string `xml:"vmsRecord>vmsRecord>vmsLocation>locationForDisplay>longitude"`
-}
-
-// Used to parse XML directly into a map instead of a slice
-type locationRecordMap map[string]location
-
-func (l *locationRecordMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	r := location{}
-	err := d.DecodeElement(&r, &start)
-	if err != nil {
-		return err
-	}
-
-	(*l)[r.Id] = r
-
-	return nil
-}
-
 func (l *vms) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 
 	// Can't unmarshal a child's attribute directly, so we need some sub-struct trickery
@@ -93,21 +79,6 @@ func imagesFromFile(file []byte) (map[string][]byte, error) {
 
 }
 
-func parseLocations(locationFile []byte, expectedSize int) (locationRecordMap, error) {
-	locations := struct {
-		Locations locationRecordMap `xml:"Body>d2LogicalModel>payloadPublication>vmsUnitTable>vmsUnitRecord"`
-	}{
-		Locations: make(locationRecordMap, expectedSize),
-	}
-
-	err := xml.Unmarshal(locationFile, &locations)
-	if err != nil {
-		return nil, err
-	}
-
-	return locations.Locations, nil
-}
-
 func parseVMsUnits(contentFile []byte) ([]vms, error) {
 	payload := struct {
 		Drips []vms `xml:"Body>d2LogicalModel>payloadPublication>vmsUnit"`
@@ -125,7 +96,7 @@ func ParseDripsXML(contentFile, locationFile []byte) ([]Drip, error) {
 	if err != nil {
 		return nil, err
 	}
-	locations, err := parseLocations(locationFile, len(vmsUnits))
+	locations, err := ndw.ParseLocations(locationFile, len(vmsUnits))
 	if err != nil {
 		return nil, err
 	}
@@ -138,16 +109,28 @@ func ParseDripsXML(contentFile, locationFile []byte) ([]Drip, error) {
 		nameData := description.Parse(loc.Description)
 
 		drips[i] = Drip{
-			Id:           d.Id,
-			Lat:          loc.Latitude,
-			Lon:          loc.Longitude,
-			Name:         nameData.Name,
-			Working:      d.Working,
-			RoadId:       nameData.RoadId,
-			RoadSide:     nameData.RoadSide,
-			RoadOffset:   nameData.RoadOffset,
-			Organization: nameData.Organization,
-			TextLines:    d.Text,
+			Id:               d.Id,
+			Lat:              loc.Latitude,
+			Lon:              loc.Longitude,
+			Name:             nameData.Name,
+			Working:          d.Working,
+			RoadId:           nameData.RoadId,
+			RoadSide:         nameData.RoadSide,
+			RoadOffset:       nameData.RoadOffset,
+			Carriageway:      string(nameData.Carriageway),
+			Junction:         nameData.Junction,
+			HectometerLetter: nameData.HectometerLetter,
+			Organization:     nameData.Organization,
+			OrganizationCode: nameData.OrganizationCode,
+			TextLines:        d.Text,
+			Routes:           traveltime.Parse(d.Text),
+		}
+
+		lat, lon, status := coordinate.Parse(loc.Latitude, loc.Longitude)
+		drips[i].CoordinateStatus = status
+		if status != coordinate.StatusInvalid {
+			drips[i].Latitude, drips[i].Longitude = lat, lon
+			drips[i].RdX, drips[i].RdY = coordinate.WGS84ToRD(lat, lon)
 		}
 
 		img, err := base64.StdEncoding.DecodeString(d.Image)
@@ -159,16 +142,59 @@ func ParseDripsXML(contentFile, locationFile []byte) ([]Drip, error) {
 			continue // Ignore empty images
 		}
 
-		image, err := png.Decode(bytes.NewReader(img))
+		decoded, err := png.Decode(bytes.NewReader(img))
 
 		if err != nil {
 			continue // Ignore faulty images
 		}
 
-		drips[i].image = img
-		drips[i].ImageWidth = image.Bounds().Dx()
-		drips[i].ImageHeight = image.Bounds().Dy()
+		setImage(&drips[i], img, decoded)
+	}
+
+	renderTextPanels(drips)
+
+	for i := range drips {
+		drips[i].DisplayState = detectDisplayState(drips[i])
 	}
 
 	return drips, nil
 }
+
+// Text-only panels get an image of their text, so they show up like any other panel
+// Lines are trimmed first, panels showing only spaces would otherwise become black markers
+func renderTextPanels(drips []Drip) {
+	for i := range drips {
+		if drips[i].hasImage() || !drips[i].hasText() {
+			continue
+		}
+
+		rendered := textpanel.Render(drips[i].TextLines)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, rendered); err != nil {
+			continue
+		}
+
+		// Only stored, analysing or hashing it would report the rendering rather than the panel
+		storeImage(&drips[i], buf.Bytes(), rendered)
+		drips[i].ImageRendered = true
+	}
+}
+
+func setImage(d *Drip, img []byte, decoded image.Image) {
+	storeImage(d, img, decoded)
+	d.PerceptualHash = imageinfo.DHash(decoded)
+
+	analysis := imageinfo.Analyze(decoded)
+	d.ImageAnalysis = &analysis
+}
+
+// Keeps the image with its size and content hash, enough to serve it
+func storeImage(d *Drip, img []byte, decoded image.Image) {
+	d.image = img
+	d.ImageWidth = decoded.Bounds().Dx()
+	d.ImageHeight = decoded.Bounds().Dy()
+
+	hash := sha256.Sum256(img)
+	d.ImageHash = hex.EncodeToString(hash[:])
+}
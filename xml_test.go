@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"testing"
+
+	"github.com/hunternl/trafficmap/coordinate"
+	"github.com/hunternl/trafficmap/ndw"
 )
 
 func assert[T comparable](t *testing.T, real, expected T) {
@@ -45,9 +50,25 @@ func TestXMLParsing(t *testing.T) {
 	assert(t, drip2.hasImage(), true)
 	assert(t, drip2.ImageWidth, 40)
 	assert(t, drip2.ImageHeight, 40)
+	assert(t, len(drip2.ImageHash), 64)
+	assert(t, drip2.ImageRendered, false)
+
+	// ID_1 only has text, so it gets a rendered image that isn't analysed
+	assert(t, drip1.hasImage(), false)
+	assert(t, drip1.hasMarkerImage(), true)
+	assert(t, drip1.ImageRendered, true)
+	assert(t, len(drip1.ImageHash), 64)
+	assert(t, drip1.ImageWidth > 0 && drip1.ImageHeight > 0, true)
+	assert(t, drip1.ImageAnalysis == nil, true)
+	assert(t, drip3.hasMarkerImage(), false)
 
 	assert(t, drip1.Lat, "52.1")
 	assert(t, drip1.Lon, "4.2")
+	assert(t, drip1.Latitude, 52.1)
+	assert(t, drip1.Longitude, 4.2)
+	assert(t, drip1.CoordinateStatus, coordinate.StatusValid)
+	assert(t, int(drip1.RdX), 73656)
+	assert(t, int(drip1.RdY), 457526)
 
 	assert(t, drip1.Name, "Description 1")
 
@@ -60,3 +81,83 @@ func TestXMLParsing(t *testing.T) {
 	assert(t, drip1.TextLines[2], "Textline 3")
 
 }
+
+func addXMLSeeds(f *testing.F) {
+	vmsUnits, err := os.ReadFile("./testdata/vmsUnit.xml")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	vmsRecords, err := os.ReadFile("./testdata/vmsRecord.xml")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(vmsUnits, vmsRecords)
+	f.Add([]byte{}, []byte{})
+}
+
+func FuzzParseDripsXML(f *testing.F) {
+	addXMLSeeds(f)
+
+	f.Fuzz(func(t *testing.T, vmsUnits, vmsRecords []byte) {
+		drips, err := ParseDripsXML(vmsUnits, vmsRecords)
+		if err != nil {
+			return
+		}
+
+		for _, drip := range drips {
+			if drip.RoadOffset != -1 && drip.RoadOffset < 0 {
+				t.Errorf("RoadOffset should be -1 or non-negative, got %v", drip.RoadOffset)
+			}
+
+			if drip.RoadSide != "" && drip.RoadSide != "L" && drip.RoadSide != "R" {
+				t.Errorf("RoadSide should be empty, L or R, got %q", drip.RoadSide)
+			}
+
+			if drip.hasImage() && (drip.ImageWidth <= 0 || drip.ImageHeight <= 0) {
+				t.Errorf("Drip %q has an image without dimensions", drip.Id)
+			}
+		}
+
+		again, err := ParseDripsXML(vmsUnits, vmsRecords)
+		if err != nil {
+			t.Fatalf("Parsing the same input twice failed the second time: %v", err)
+		}
+
+		first, _ := json.Marshal(drips)
+		second, _ := json.Marshal(again)
+		if !bytes.Equal(first, second) {
+			t.Errorf("Parsing the same input twice gave different output")
+		}
+	})
+}
+
+func FuzzUnmarshalVms(f *testing.F) {
+	addXMLSeeds(f)
+
+	f.Fuzz(func(t *testing.T, vmsUnits, vmsRecords []byte) {
+		// Only checks for panics, any error is fine
+		parseVMsUnits(vmsUnits)
+		ndw.ParseLocations(vmsRecords, 0)
+	})
+}
+
+func TestRenderTextPanels(t *testing.T) {
+	drips := []Drip{
+		{Id: "TEXT", TextLines: []string{"  FILE  ", "A2 12 MIN"}},
+		{Id: "SPACES", TextLines: []string{"     ", "   "}},
+		{Id: "NOTHING"},
+	}
+
+	renderTextPanels(drips)
+
+	assert(t, drips[0].hasMarkerImage(), true)
+	assert(t, drips[0].ImageRendered, true)
+
+	// Whitespace-only panels show nothing, so they don't get a black marker image
+	for _, drip := range drips[1:] {
+		assert(t, drip.hasMarkerImage(), false)
+		assert(t, drip.ImageRendered, false)
+	}
+}